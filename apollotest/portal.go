@@ -0,0 +1,710 @@
+package apollotest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	OPENAPI_PREFIX = "/openapi/v1/"
+
+	FORMAT_PROPERTIES = "properties"
+)
+
+// 内存版的Apollo Portal Open API，发布的配置会同步到关联的config service（Server），
+// 所有环境（env）都对应同一个Server
+type Portal struct {
+	URL string
+
+	server     *Server
+	token      string
+	httpServer *httptest.Server
+	mu         sync.Mutex
+	apps       map[string]*portalApp
+	namespaces map[string]*portalNamespace
+	releases   map[int64]*portalRelease
+	releaseId  int64
+	branchId   int64
+}
+
+type portalApp struct {
+	appId    string
+	name     string
+	clusters []string
+}
+
+type portalNamespace struct {
+	appId    string
+	cluster  string
+	name     string
+	format   string
+	isPublic bool
+	comment  string
+	items    []*portalItem
+	//已发布的版本（按发布顺序），回滚时回到上一个未回滚的版本
+	releases []*portalRelease
+	//灰度分支，分支本身也是一个namespace，cluster为分支名
+	branch *portalNamespace
+	parent *portalNamespace
+	rules  []grayRuleItem
+}
+
+type grayRuleItem struct {
+	ClientAppId     string   `json:"clientAppId"`
+	ClientIpList    []string `json:"clientIpList"`
+	ClientLabelList []string `json:"clientLabelList"`
+}
+
+type portalItem struct {
+	key        string
+	value      string
+	comment    string
+	createdBy  string
+	modifiedBy string
+}
+
+type portalRelease struct {
+	id             int64
+	namespace      *portalNamespace
+	name           string
+	comment        string
+	releaseKey     string
+	configurations map[string]string
+	createdBy      string
+	abandoned      bool
+}
+
+// Open API返回的错误
+type portalError struct {
+	status  int
+	message string
+}
+
+// 启动一个关联到server的Portal测试服务，token为Open API的访问令牌，为空时不校验
+func NewPortal(server *Server, token string) *Portal {
+	p := &Portal{
+		server:     server,
+		token:      token,
+		apps:       map[string]*portalApp{},
+		namespaces: map[string]*portalNamespace{},
+		releases:   map[int64]*portalRelease{},
+	}
+	p.httpServer = httptest.NewServer(p)
+	p.URL = p.httpServer.URL
+	return p
+}
+
+// 关闭测试服务
+func (p *Portal) Close() {
+	p.httpServer.Close()
+}
+
+// 创建应用，每个集群都会创建默认的 application namespace，clusters为空时只有default集群
+func (p *Portal) CreateApp(appId, name string, clusters ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(clusters) == 0 {
+		clusters = []string{"default"}
+	}
+	app := &portalApp{appId: appId, name: name, clusters: append([]string(nil), clusters...)}
+	sort.Strings(app.clusters)
+	p.apps[appId] = app
+	for _, cluster := range app.clusters {
+		p.namespaces[watchKey(appId, cluster, "application")] = &portalNamespace{
+			appId:   appId,
+			cluster: cluster,
+			name:    "application",
+			format:  FORMAT_PROPERTIES,
+		}
+	}
+}
+
+func (p *Portal) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if p.token != "" && r.Header.Get("Authorization") != p.token {
+		writePortalError(w, &portalError{http.StatusUnauthorized, "Unauthorized"})
+		return
+	}
+	if !strings.HasPrefix(r.URL.EscapedPath(), OPENAPI_PREFIX) {
+		http.NotFound(w, r)
+		return
+	}
+	var segments []string
+	for _, segment := range strings.Split(strings.TrimPrefix(r.URL.EscapedPath(), OPENAPI_PREFIX), "/") {
+		unescaped, err := url.PathUnescape(segment)
+		if err != nil {
+			writePortalError(w, &portalError{http.StatusBadRequest, err.Error()})
+			return
+		}
+		segments = append(segments, unescaped)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	result, perr := p.route(r, segments)
+	if perr != nil {
+		writePortalError(w, perr)
+		return
+	}
+	if result == nil {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	writeJSON(w, result)
+}
+
+// 按请求方法和路径分发到对应的处理方法
+func (p *Portal) route(r *http.Request, s []string) (interface{}, *portalError) {
+	method := r.Method
+	switch {
+	case len(s) == 1 && s[0] == "apps" && method == http.MethodGet:
+		return p.listApps(r)
+	case len(s) == 3 && s[0] == "apps" && s[2] == "envclusters" && method == http.MethodGet:
+		return p.envClusters(s[1])
+	case len(s) == 3 && s[0] == "apps" && s[2] == "appnamespaces" && method == http.MethodPost:
+		return p.createNamespace(r, s[1])
+	case len(s) == 5 && s[0] == "envs" && s[2] == "releases" && s[4] == "rollback" && method == http.MethodPut:
+		return p.rollback(r, s[3])
+	case len(s) >= 6 && s[0] == "envs" && s[2] == "apps" && s[4] == "clusters":
+		return p.routeCluster(r, s[3], s[5], s[6:])
+	}
+	return nil, &portalError{http.StatusNotFound, "Not Found"}
+}
+
+// 处理 /envs/{env}/apps/{appId}/clusters/{cluster} 下的请求
+func (p *Portal) routeCluster(r *http.Request, appId, cluster string, s []string) (interface{}, *portalError) {
+	method := r.Method
+	if len(s) == 0 && method == http.MethodGet {
+		return p.getCluster(appId, cluster)
+	}
+	if len(s) == 1 && s[0] == "namespaces" && method == http.MethodGet {
+		return p.listNamespaces(appId, cluster)
+	}
+	if len(s) < 2 || s[0] != "namespaces" {
+		return nil, &portalError{http.StatusNotFound, "Not Found"}
+	}
+	ns, ok := p.namespaces[watchKey(appId, cluster, s[1])]
+	if !ok {
+		return nil, &portalError{http.StatusNotFound, fmt.Sprintf("namespace not found for %s %s %s", appId, cluster, s[1])}
+	}
+	s = s[2:]
+	switch {
+	case len(s) == 0 && method == http.MethodGet:
+		return ns.dto(), nil
+	case len(s) == 1 && s[0] == "items" && method == http.MethodPost:
+		return p.createItem(r, ns)
+	case len(s) == 2 && s[0] == "items" && method == http.MethodGet:
+		return p.getItem(ns, s[1])
+	case len(s) == 2 && s[0] == "items" && method == http.MethodPut:
+		return p.updateItem(r, ns, s[1])
+	case len(s) == 2 && s[0] == "items" && method == http.MethodDelete:
+		return p.deleteItem(r, ns, s[1])
+	case len(s) == 1 && s[0] == "releases" && method == http.MethodPost:
+		return p.publish(r, ns)
+	case len(s) == 2 && s[0] == "releases" && s[1] == "latest" && method == http.MethodGet:
+		return p.latestRelease(ns)
+	case len(s) >= 1 && s[0] == "branches":
+		return p.routeBranch(r, ns, s[1:])
+	}
+	return nil, &portalError{http.StatusNotFound, "Not Found"}
+}
+
+// 处理 /envs/{env}/apps/{appId}/clusters/{cluster}/namespaces/{namespace}/branches 下的请求
+func (p *Portal) routeBranch(r *http.Request, ns *portalNamespace, s []string) (interface{}, *portalError) {
+	method := r.Method
+	switch {
+	case len(s) == 0 && method == http.MethodGet:
+		if ns.branch == nil {
+			return nil, nil
+		}
+		return ns.branch.dto(), nil
+	case len(s) == 0 && method == http.MethodPost:
+		return p.createBranch(r, ns)
+	}
+	if len(s) == 0 || ns.branch == nil || ns.branch.cluster != s[0] {
+		return nil, &portalError{http.StatusNotFound, "branch not found"}
+	}
+	branch := ns.branch
+	s = s[1:]
+	switch {
+	case len(s) == 0 && method == http.MethodDelete:
+		return p.deleteBranch(r, ns)
+	case len(s) == 1 && s[0] == "rules" && method == http.MethodGet:
+		return branch.rulesDto(), nil
+	case len(s) == 1 && s[0] == "rules" && method == http.MethodPut:
+		return p.updateRules(r, branch)
+	case len(s) == 1 && s[0] == "releases" && method == http.MethodPost:
+		return p.publishGray(r, branch)
+	case len(s) == 1 && s[0] == "merge" && method == http.MethodPost:
+		return p.merge(r, ns)
+	}
+	return nil, &portalError{http.StatusNotFound, "Not Found"}
+}
+
+func (p *Portal) listApps(r *http.Request) (interface{}, *portalError) {
+	var appIds []string
+	if query := r.URL.Query().Get("appIds"); query != "" {
+		appIds = strings.Split(query, ",")
+	} else {
+		for appId := range p.apps {
+			appIds = append(appIds, appId)
+		}
+		sort.Strings(appIds)
+	}
+	apps := make([]map[string]interface{}, 0, len(appIds))
+	for _, appId := range appIds {
+		if app, ok := p.apps[appId]; ok {
+			apps = append(apps, map[string]interface{}{"appId": app.appId, "name": app.name})
+		}
+	}
+	return apps, nil
+}
+
+func (p *Portal) envClusters(appId string) (interface{}, *portalError) {
+	app, ok := p.apps[appId]
+	if !ok {
+		return nil, &portalError{http.StatusNotFound, "app not found: " + appId}
+	}
+	return []map[string]interface{}{{"env": "DEV", "clusters": app.clusters}}, nil
+}
+
+func (p *Portal) getCluster(appId, cluster string) (interface{}, *portalError) {
+	app, ok := p.apps[appId]
+	if !ok {
+		return nil, &portalError{http.StatusNotFound, "app not found: " + appId}
+	}
+	for _, name := range app.clusters {
+		if name == cluster {
+			return map[string]interface{}{"appId": appId, "name": cluster}, nil
+		}
+	}
+	return nil, &portalError{http.StatusNotFound, "cluster not found: " + cluster}
+}
+
+func (p *Portal) listNamespaces(appId, cluster string) (interface{}, *portalError) {
+	if _, perr := p.getCluster(appId, cluster); perr != nil {
+		return nil, perr
+	}
+	var names []string
+	for _, ns := range p.namespaces {
+		if ns.appId == appId && ns.cluster == cluster {
+			names = append(names, ns.name)
+		}
+	}
+	sort.Strings(names)
+	namespaces := make([]map[string]interface{}, 0, len(names))
+	for _, name := range names {
+		namespaces = append(namespaces, p.namespaces[watchKey(appId, cluster, name)].dto())
+	}
+	return namespaces, nil
+}
+
+func (p *Portal) createNamespace(r *http.Request, appId string) (interface{}, *portalError) {
+	var req struct {
+		Name                string `json:"name"`
+		AppId               string `json:"appId"`
+		Format              string `json:"format"`
+		IsPublic            bool   `json:"isPublic"`
+		Comment             string `json:"comment"`
+		DataChangeCreatedBy string `json:"dataChangeCreatedBy"`
+	}
+	if perr := decodeBody(r, &req); perr != nil {
+		return nil, perr
+	}
+	app, ok := p.apps[appId]
+	if !ok {
+		return nil, &portalError{http.StatusNotFound, "app not found: " + appId}
+	}
+	if req.Name == "" || req.DataChangeCreatedBy == "" {
+		return nil, &portalError{http.StatusBadRequest, "name and dataChangeCreatedBy should not be empty"}
+	}
+	if req.Format == "" {
+		req.Format = FORMAT_PROPERTIES
+	}
+	name := req.Name
+	if req.Format != FORMAT_PROPERTIES && !strings.HasSuffix(name, "."+req.Format) {
+		name += "." + req.Format
+	}
+	for _, cluster := range app.clusters {
+		if _, ok := p.namespaces[watchKey(appId, cluster, name)]; ok {
+			return nil, &portalError{http.StatusBadRequest, "namespace already exists: " + name}
+		}
+	}
+	for _, cluster := range app.clusters {
+		p.namespaces[watchKey(appId, cluster, name)] = &portalNamespace{
+			appId:    appId,
+			cluster:  cluster,
+			name:     name,
+			format:   req.Format,
+			isPublic: req.IsPublic,
+			comment:  req.Comment,
+		}
+	}
+	return map[string]interface{}{
+		"name":                name,
+		"appId":               appId,
+		"format":              req.Format,
+		"isPublic":            req.IsPublic,
+		"comment":             req.Comment,
+		"dataChangeCreatedBy": req.DataChangeCreatedBy,
+	}, nil
+}
+
+func (p *Portal) createItem(r *http.Request, ns *portalNamespace) (interface{}, *portalError) {
+	var req itemRequest
+	if perr := decodeBody(r, &req); perr != nil {
+		return nil, perr
+	}
+	if req.Key == "" || req.DataChangeCreatedBy == "" {
+		return nil, &portalError{http.StatusBadRequest, "key and dataChangeCreatedBy should not be empty"}
+	}
+	if ns.item(req.Key) != nil {
+		return nil, &portalError{http.StatusBadRequest, "item already exists: " + req.Key}
+	}
+	item := &portalItem{key: req.Key, value: req.Value, comment: req.Comment, createdBy: req.DataChangeCreatedBy, modifiedBy: req.DataChangeCreatedBy}
+	ns.items = append(ns.items, item)
+	return item.dto(), nil
+}
+
+func (p *Portal) getItem(ns *portalNamespace, key string) (interface{}, *portalError) {
+	item := ns.item(key)
+	if item == nil {
+		return nil, &portalError{http.StatusNotFound, "item not found: " + key}
+	}
+	return item.dto(), nil
+}
+
+func (p *Portal) updateItem(r *http.Request, ns *portalNamespace, key string) (interface{}, *portalError) {
+	var req itemRequest
+	if perr := decodeBody(r, &req); perr != nil {
+		return nil, perr
+	}
+	if req.Key != key || req.DataChangeLastModifiedBy == "" {
+		return nil, &portalError{http.StatusBadRequest, "key should match path and dataChangeLastModifiedBy should not be empty"}
+	}
+	item := ns.item(key)
+	if item == nil {
+		if r.URL.Query().Get("createIfNotExists") != "true" {
+			return nil, &portalError{http.StatusNotFound, "item not found: " + key}
+		}
+		item = &portalItem{key: key, createdBy: req.DataChangeLastModifiedBy}
+		ns.items = append(ns.items, item)
+	}
+	item.value, item.comment, item.modifiedBy = req.Value, req.Comment, req.DataChangeLastModifiedBy
+	return nil, nil
+}
+
+func (p *Portal) deleteItem(r *http.Request, ns *portalNamespace, key string) (interface{}, *portalError) {
+	if r.URL.Query().Get("operator") == "" {
+		return nil, &portalError{http.StatusBadRequest, "operator should not be empty"}
+	}
+	for i, item := range ns.items {
+		if item.key == key {
+			ns.items = append(ns.items[:i], ns.items[i+1:]...)
+			return nil, nil
+		}
+	}
+	return nil, &portalError{http.StatusNotFound, "item not found: " + key}
+}
+
+// 发布namespace当前的所有配置项，并同步到config service
+func (p *Portal) publish(r *http.Request, ns *portalNamespace) (interface{}, *portalError) {
+	var req releaseRequest
+	if perr := decodeBody(r, &req); perr != nil {
+		return nil, perr
+	}
+	if req.ReleaseTitle == "" || req.ReleasedBy == "" {
+		return nil, &portalError{http.StatusBadRequest, "releaseTitle and releasedBy should not be empty"}
+	}
+	configurations := make(map[string]string, len(ns.items))
+	for _, item := range ns.items {
+		configurations[item.key] = item.value
+	}
+	releaseKey := p.server.Publish(ns.appId, ns.cluster, ns.name, configurations)
+	rel := p.newRelease(ns, req, releaseKey, configurations)
+	ns.releases = append(ns.releases, rel)
+	return rel.dto(), nil
+}
+
+// 创建灰度分支，每个namespace同时只能有一个分支
+func (p *Portal) createBranch(r *http.Request, ns *portalNamespace) (interface{}, *portalError) {
+	if r.URL.Query().Get("operator") == "" {
+		return nil, &portalError{http.StatusBadRequest, "operator should not be empty"}
+	}
+	if ns.branch != nil {
+		return nil, &portalError{http.StatusBadRequest, "branch already exists: " + ns.branch.cluster}
+	}
+	p.branchId++
+	ns.branch = &portalNamespace{
+		appId:   ns.appId,
+		cluster: fmt.Sprintf("%s-%d", time.Now().Format("20060102150405"), p.branchId),
+		name:    ns.name,
+		format:  ns.format,
+		comment: ns.comment,
+		parent:  ns,
+	}
+	p.namespaces[watchKey(ns.appId, ns.branch.cluster, ns.name)] = ns.branch
+	return ns.branch.dto(), nil
+}
+
+// 删除（放弃）灰度分支，已发布的灰度配置同时失效
+func (p *Portal) deleteBranch(r *http.Request, ns *portalNamespace) (interface{}, *portalError) {
+	if r.URL.Query().Get("operator") == "" {
+		return nil, &portalError{http.StatusBadRequest, "operator should not be empty"}
+	}
+	p.removeBranch(ns)
+	return nil, nil
+}
+
+func (p *Portal) removeBranch(ns *portalNamespace) {
+	delete(p.namespaces, watchKey(ns.appId, ns.branch.cluster, ns.name))
+	if len(ns.branch.activeReleases()) > 0 {
+		p.server.AbandonGray(ns.appId, ns.cluster, ns.name)
+	}
+	ns.branch = nil
+}
+
+// 更新灰度规则，已有灰度发布时立即生效
+func (p *Portal) updateRules(r *http.Request, branch *portalNamespace) (interface{}, *portalError) {
+	if r.URL.Query().Get("operator") == "" {
+		return nil, &portalError{http.StatusBadRequest, "operator should not be empty"}
+	}
+	var req struct {
+		RuleItems []grayRuleItem `json:"ruleItems"`
+	}
+	if perr := decodeBody(r, &req); perr != nil {
+		return nil, perr
+	}
+	branch.rules = req.RuleItems
+	if len(branch.activeReleases()) > 0 {
+		parent := branch.parent
+		p.server.setGrayRule(parent.appId, parent.cluster, parent.name, branch.grayRule())
+	}
+	return nil, nil
+}
+
+// 灰度发布，灰度配置为主版本最新发布的配置叠加分支的配置项
+func (p *Portal) publishGray(r *http.Request, branch *portalNamespace) (interface{}, *portalError) {
+	var req releaseRequest
+	if perr := decodeBody(r, &req); perr != nil {
+		return nil, perr
+	}
+	if req.ReleaseTitle == "" || req.ReleasedBy == "" {
+		return nil, &portalError{http.StatusBadRequest, "releaseTitle and releasedBy should not be empty"}
+	}
+	parent := branch.parent
+	configurations := map[string]string{}
+	if active := parent.activeReleases(); len(active) > 0 {
+		for key, value := range active[len(active)-1].configurations {
+			configurations[key] = value
+		}
+	}
+	for _, item := range branch.items {
+		configurations[item.key] = item.value
+	}
+	releaseKey := p.server.PublishGray(parent.appId, parent.cluster, parent.name, branch.grayRule(), configurations)
+	rel := p.newRelease(branch, req, releaseKey, configurations)
+	branch.releases = append(branch.releases, rel)
+	return rel.dto(), nil
+}
+
+// 将分支的配置项合并到主版本并发布，deleteBranch为true时同时删除分支
+func (p *Portal) merge(r *http.Request, ns *portalNamespace) (interface{}, *portalError) {
+	var req releaseRequest
+	if perr := decodeBody(r, &req); perr != nil {
+		return nil, perr
+	}
+	if req.ReleaseTitle == "" || req.ReleasedBy == "" {
+		return nil, &portalError{http.StatusBadRequest, "releaseTitle and releasedBy should not be empty"}
+	}
+	for _, branchItem := range ns.branch.items {
+		item := ns.item(branchItem.key)
+		if item == nil {
+			item = &portalItem{key: branchItem.key, createdBy: req.ReleasedBy}
+			ns.items = append(ns.items, item)
+		}
+		item.value, item.comment, item.modifiedBy = branchItem.value, branchItem.comment, req.ReleasedBy
+	}
+	if r.URL.Query().Get("deleteBranch") == "true" {
+		p.removeBranch(ns)
+	}
+	configurations := make(map[string]string, len(ns.items))
+	for _, item := range ns.items {
+		configurations[item.key] = item.value
+	}
+	releaseKey := p.server.Publish(ns.appId, ns.cluster, ns.name, configurations)
+	rel := p.newRelease(ns, req, releaseKey, configurations)
+	ns.releases = append(ns.releases, rel)
+	return rel.dto(), nil
+}
+
+// 获取最新的未回滚版本，没有发布时返回空响应
+func (p *Portal) latestRelease(ns *portalNamespace) (interface{}, *portalError) {
+	active := ns.activeReleases()
+	if len(active) == 0 {
+		return nil, nil
+	}
+	return active[len(active)-1].dto(), nil
+}
+
+// 回滚到上一个未回滚的版本，并同步到config service
+func (p *Portal) rollback(r *http.Request, releaseId string) (interface{}, *portalError) {
+	if r.URL.Query().Get("operator") == "" {
+		return nil, &portalError{http.StatusBadRequest, "operator should not be empty"}
+	}
+	id, err := strconv.ParseInt(releaseId, 10, 64)
+	if err != nil {
+		return nil, &portalError{http.StatusBadRequest, err.Error()}
+	}
+	rel, ok := p.releases[id]
+	if !ok {
+		return nil, &portalError{http.StatusNotFound, "release not found: " + releaseId}
+	}
+	ns := rel.namespace
+	if ns.parent != nil {
+		return nil, &portalError{http.StatusBadRequest, "gray release can not be rolled back: " + releaseId}
+	}
+	active := ns.activeReleases()
+	if len(active) == 0 || active[len(active)-1] != rel {
+		return nil, &portalError{http.StatusBadRequest, "release is not the latest active release: " + releaseId}
+	}
+	if len(active) < 2 {
+		return nil, &portalError{http.StatusBadRequest, "no release to rollback to"}
+	}
+	rel.abandoned = true
+	previous := active[len(active)-2]
+	p.server.publishRelease(ns.appId, ns.cluster, ns.name, &release{releaseKey: previous.releaseKey, configurations: previous.configurations})
+	return nil, nil
+}
+
+func (p *Portal) newRelease(ns *portalNamespace, req releaseRequest, releaseKey string, configurations map[string]string) *portalRelease {
+	p.releaseId++
+	rel := &portalRelease{
+		id:             p.releaseId,
+		namespace:      ns,
+		name:           req.ReleaseTitle,
+		comment:        req.ReleaseComment,
+		releaseKey:     releaseKey,
+		configurations: configurations,
+		createdBy:      req.ReleasedBy,
+	}
+	p.releases[rel.id] = rel
+	return rel
+}
+
+type itemRequest struct {
+	Key                      string `json:"key"`
+	Value                    string `json:"value"`
+	Comment                  string `json:"comment"`
+	DataChangeCreatedBy      string `json:"dataChangeCreatedBy"`
+	DataChangeLastModifiedBy string `json:"dataChangeLastModifiedBy"`
+}
+
+type releaseRequest struct {
+	ReleaseTitle   string `json:"releaseTitle"`
+	ReleaseComment string `json:"releaseComment"`
+	ReleasedBy     string `json:"releasedBy"`
+}
+
+func (ns *portalNamespace) item(key string) *portalItem {
+	for _, item := range ns.items {
+		if item.key == key {
+			return item
+		}
+	}
+	return nil
+}
+
+func (ns *portalNamespace) activeReleases() []*portalRelease {
+	var active []*portalRelease
+	for _, rel := range ns.releases {
+		if !rel.abandoned {
+			active = append(active, rel)
+		}
+	}
+	return active
+}
+
+func (ns *portalNamespace) dto() map[string]interface{} {
+	items := make([]map[string]interface{}, 0, len(ns.items))
+	for _, item := range ns.items {
+		items = append(items, item.dto())
+	}
+	return map[string]interface{}{
+		"appId":         ns.appId,
+		"clusterName":   ns.cluster,
+		"namespaceName": ns.name,
+		"comment":       ns.comment,
+		"format":        ns.format,
+		"isPublic":      ns.isPublic,
+		"items":         items,
+	}
+}
+
+// 合并所有规则的ip和标签
+func (ns *portalNamespace) grayRule() GrayRule {
+	var rule GrayRule
+	for _, item := range ns.rules {
+		rule.ClientIps = append(rule.ClientIps, item.ClientIpList...)
+		rule.ClientLabels = append(rule.ClientLabels, item.ClientLabelList...)
+	}
+	return rule
+}
+
+func (ns *portalNamespace) rulesDto() map[string]interface{} {
+	ruleItems := ns.rules
+	if ruleItems == nil {
+		ruleItems = []grayRuleItem{}
+	}
+	return map[string]interface{}{
+		"appId":         ns.appId,
+		"clusterName":   ns.parent.cluster,
+		"namespaceName": ns.name,
+		"branchName":    ns.cluster,
+		"ruleItems":     ruleItems,
+	}
+}
+
+func (item *portalItem) dto() map[string]interface{} {
+	return map[string]interface{}{
+		"key":                      item.key,
+		"value":                    item.value,
+		"comment":                  item.comment,
+		"dataChangeCreatedBy":      item.createdBy,
+		"dataChangeLastModifiedBy": item.modifiedBy,
+	}
+}
+
+func (rel *portalRelease) dto() map[string]interface{} {
+	return map[string]interface{}{
+		"id":                  rel.id,
+		"appId":               rel.namespace.appId,
+		"clusterName":         rel.namespace.cluster,
+		"namespaceName":       rel.namespace.name,
+		"name":                rel.name,
+		"comment":             rel.comment,
+		"configurations":      rel.configurations,
+		"dataChangeCreatedBy": rel.createdBy,
+	}
+}
+
+func decodeBody(r *http.Request, v interface{}) *portalError {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return &portalError{http.StatusBadRequest, err.Error()}
+	}
+	return nil
+}
+
+// 与Apollo Portal一致的错误格式
+func writePortalError(w http.ResponseWriter, perr *portalError) {
+	w.Header().Set("Content-Type", "application/json;charset=UTF-8")
+	w.WriteHeader(perr.status)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"status": perr.status, "message": perr.message})
+}
@@ -0,0 +1,404 @@
+// Package apollotest 提供一个基于 httptest 的内存版 Apollo config service，
+// 用于在没有网络的环境下测试客户端
+package apollotest
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DEFAULT_LONG_POLL_TIMEOUT = 60 * time.Second
+	DEFAULT_SIGNATURE_WINDOW  = 60 * time.Second
+
+	CONFIG_SERVICE_APP_NAME = "APOLLO-CONFIGSERVICE"
+	GRAY_RULE_ALL           = "*"
+)
+
+// 灰度规则，命中ip或标签的客户端会读取到灰度配置，ip支持通配符 *
+type GrayRule struct {
+	ClientIps    []string
+	ClientLabels []string
+}
+
+type release struct {
+	releaseKey     string
+	configurations map[string]string
+}
+
+type namespace struct {
+	notificationId int64
+	main           *release
+	gray           *release
+	grayRule       GrayRule
+}
+
+// 内存版的Apollo config service
+type Server struct {
+	URL string
+	//长轮询无变更时的挂起时间
+	LongPollTimeout time.Duration
+
+	httpServer     *httptest.Server
+	mu             sync.Mutex
+	changed        chan struct{}
+	notificationId int64
+	namespaces     map[string]*namespace
+	secrets        map[string]string
+	requests       map[string]int
+}
+
+// 启动一个http协议的测试服务
+func NewServer() *Server {
+	s := newServer()
+	s.httpServer = httptest.NewServer(s)
+	s.URL = s.httpServer.URL
+	return s
+}
+
+// 启动一个https协议的测试服务，证书可以通过 HttpServer().Certificate() 获取
+func NewTLSServer() *Server {
+	s := newServer()
+	s.httpServer = httptest.NewTLSServer(s)
+	s.URL = s.httpServer.URL
+	return s
+}
+
+func newServer() *Server {
+	return &Server{
+		LongPollTimeout: DEFAULT_LONG_POLL_TIMEOUT,
+		changed:         make(chan struct{}),
+		namespaces:      map[string]*namespace{},
+		secrets:         map[string]string{},
+		requests:        map[string]int{},
+	}
+}
+
+// 关闭测试服务
+func (s *Server) Close() {
+	s.httpServer.Close()
+}
+
+// 获取底层的httptest.Server
+func (s *Server) HttpServer() *httptest.Server {
+	return s.httpServer
+}
+
+// 设置应用的访问秘钥，设置后该应用的请求都需要通过签名校验
+func (s *Server) SetSecret(appId, secret string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.secrets[appId] = secret
+}
+
+// 发布主版本配置，返回新的releaseKey
+func (s *Server) Publish(appId, cluster, namespaceName string, configurations map[string]string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rel := s.newRelease(configurations)
+	s.setMain(appId, cluster, namespaceName, rel)
+	return rel.releaseKey
+}
+
+// 将指定版本重新发布为主版本，Portal回滚时使用
+func (s *Server) publishRelease(appId, cluster, namespaceName string, rel *release) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.setMain(appId, cluster, namespaceName, rel)
+}
+
+func (s *Server) setMain(appId, cluster, namespaceName string, rel *release) {
+	ns := s.namespace(appId, cluster, namespaceName)
+	ns.main = rel
+	s.notify(ns)
+}
+
+// 发布灰度配置，命中灰度规则的客户端会读取到该配置，返回新的releaseKey
+func (s *Server) PublishGray(appId, cluster, namespaceName string, rule GrayRule, configurations map[string]string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ns := s.namespace(appId, cluster, namespaceName)
+	ns.gray = s.newRelease(configurations)
+	ns.grayRule = rule
+	s.notify(ns)
+	return ns.gray.releaseKey
+}
+
+// 更新灰度规则，灰度配置不变
+func (s *Server) setGrayRule(appId, cluster, namespaceName string, rule GrayRule) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ns := s.namespace(appId, cluster, namespaceName)
+	ns.grayRule = rule
+	s.notify(ns)
+}
+
+// 删除灰度配置
+func (s *Server) AbandonGray(appId, cluster, namespaceName string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ns := s.namespace(appId, cluster, namespaceName)
+	ns.gray = nil
+	ns.grayRule = GrayRule{}
+	s.notify(ns)
+}
+
+// 删除namespace，之后读取该namespace会返回404
+func (s *Server) Delete(appId, cluster, namespaceName string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.namespaces, watchKey(appId, cluster, namespaceName))
+	s.broadcast()
+}
+
+// 设置namespace的notificationId，用于模拟已有发布历史的namespace
+func (s *Server) SetNotificationId(appId, cluster, namespaceName string, notificationId int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.namespace(appId, cluster, namespaceName).notificationId = notificationId
+	if notificationId > s.notificationId {
+		s.notificationId = notificationId
+	}
+	s.broadcast()
+}
+
+// 获取某个接口路径前缀（如 /configs、/notifications/v2）收到的请求数
+func (s *Server) RequestCount(pathPrefix string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	count := 0
+	for path, n := range s.requests {
+		if strings.HasPrefix(path, pathPrefix) {
+			count += n
+		}
+	}
+	return count
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests[r.URL.Path]++
+	s.mu.Unlock()
+
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case r.URL.Path == "/services/config":
+		s.serveServices(w)
+	case r.URL.Path == "/notifications/v2":
+		if s.verify(w, r, r.URL.Query().Get("appId")) {
+			s.serveNotifications(w, r)
+		}
+	case len(segments) == 4 && segments[0] == "configs":
+		if s.verify(w, r, segments[1]) {
+			s.serveConfigs(w, r, segments[1], segments[2], segments[3])
+		}
+	case len(segments) == 5 && segments[0] == "configfiles" && segments[1] == "json":
+		if s.verify(w, r, segments[2]) {
+			s.serveConfigFiles(w, r, segments[2], segments[3], segments[4])
+		}
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// /services/config 返回当前服务自身作为唯一的config service实例
+func (s *Server) serveServices(w http.ResponseWriter) {
+	writeJSON(w, []map[string]string{{
+		"appName":     CONFIG_SERVICE_APP_NAME,
+		"instanceId":  strings.TrimPrefix(strings.TrimPrefix(s.URL, "http://"), "https://"),
+		"homepageUrl": s.URL + "/",
+	}})
+}
+
+// /configs/{appId}/{cluster}/{namespace} 不带缓存的配置接口
+func (s *Server) serveConfigs(w http.ResponseWriter, r *http.Request, appId, cluster, namespaceName string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rel := s.release(appId, cluster, namespaceName, r)
+	if rel == nil {
+		http.NotFound(w, r)
+		return
+	}
+	if r.URL.Query().Get("releaseKey") == rel.releaseKey {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	writeJSON(w, map[string]interface{}{
+		"appId":          appId,
+		"cluster":        cluster,
+		"namespaceName":  namespaceName,
+		"configurations": rel.configurations,
+		"releaseKey":     rel.releaseKey,
+	})
+}
+
+// /configfiles/json/{appId}/{cluster}/{namespace} 带缓存的配置接口
+func (s *Server) serveConfigFiles(w http.ResponseWriter, r *http.Request, appId, cluster, namespaceName string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rel := s.release(appId, cluster, namespaceName, r)
+	if rel == nil {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, rel.configurations)
+}
+
+// /notifications/v2 长轮询接口，有变更立即返回，否则挂起 LongPollTimeout 后返回304
+func (s *Server) serveNotifications(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	appId, cluster := query.Get("appId"), query.Get("cluster")
+	var requested []struct {
+		NamespaceName  string `json:"namespaceName"`
+		NotificationId int64  `json:"notificationId"`
+	}
+	if err := json.Unmarshal([]byte(query.Get("notifications")), &requested); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	timer := time.NewTimer(s.LongPollTimeout)
+	defer timer.Stop()
+	for {
+		s.mu.Lock()
+		changed := s.changed
+		var notifications []map[string]interface{}
+		for _, n := range requested {
+			ns, ok := s.namespaces[watchKey(appId, cluster, n.NamespaceName)]
+			if !ok || ns.notificationId <= n.NotificationId {
+				continue
+			}
+			notifications = append(notifications, map[string]interface{}{
+				"namespaceName":  n.NamespaceName,
+				"notificationId": ns.notificationId,
+				"messages": map[string]interface{}{
+					"details": map[string]int64{watchKey(appId, cluster, n.NamespaceName): ns.notificationId},
+				},
+			})
+		}
+		s.mu.Unlock()
+
+		if len(notifications) > 0 {
+			writeJSON(w, notifications)
+			return
+		}
+		select {
+		case <-changed:
+		case <-timer.C:
+			w.WriteHeader(http.StatusNotModified)
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// 校验请求签名，与Apollo服务端的算法一致：base64(hmac-sha1(timestamp + "\n" + pathWithQuery))
+func (s *Server) verify(w http.ResponseWriter, r *http.Request, appId string) bool {
+	s.mu.Lock()
+	secret, ok := s.secrets[appId]
+	s.mu.Unlock()
+	if !ok || secret == "" {
+		return true
+	}
+
+	timestamp := r.Header.Get("Timestamp")
+	ms, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || absDuration(time.Since(time.UnixMilli(ms))) > DEFAULT_SIGNATURE_WINDOW {
+		http.Error(w, "RequestTimeTooSkewed", http.StatusUnauthorized)
+		return false
+	}
+	h := hmac.New(sha1.New, []byte(secret))
+	h.Write([]byte(timestamp + "\n" + r.URL.RequestURI()))
+	expect := fmt.Sprintf("Apollo %s:%s", appId, base64.StdEncoding.EncodeToString(h.Sum(nil)))
+	if !hmac.Equal([]byte(r.Header.Get("Authorization")), []byte(expect)) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+// 获取客户端应该读取到的版本，命中灰度规则时返回灰度版本
+func (s *Server) release(appId, cluster, namespaceName string, r *http.Request) *release {
+	ns, ok := s.namespaces[watchKey(appId, cluster, namespaceName)]
+	if !ok {
+		return nil
+	}
+	if ns.gray != nil && ns.grayRule.match(r.URL.Query().Get("ip"), r.URL.Query().Get("label")) {
+		return ns.gray
+	}
+	return ns.main
+}
+
+func (s *Server) namespace(appId, cluster, namespaceName string) *namespace {
+	key := watchKey(appId, cluster, namespaceName)
+	ns, ok := s.namespaces[key]
+	if !ok {
+		ns = &namespace{}
+		s.namespaces[key] = ns
+	}
+	return ns
+}
+
+func (s *Server) newRelease(configurations map[string]string) *release {
+	copied := make(map[string]string, len(configurations))
+	for key, value := range configurations {
+		copied[key] = value
+	}
+	return &release{
+		releaseKey:     fmt.Sprintf("%s-%d", time.Now().Format("20060102150405"), s.notificationId+1),
+		configurations: copied,
+	}
+}
+
+// 更新namespace的notificationId并唤醒挂起的长轮询
+func (s *Server) notify(ns *namespace) {
+	s.notificationId++
+	ns.notificationId = s.notificationId
+	s.broadcast()
+}
+
+func (s *Server) broadcast() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+func (rule GrayRule) match(ip, label string) bool {
+	for _, clientIp := range rule.ClientIps {
+		if clientIp == GRAY_RULE_ALL || (ip != "" && clientIp == ip) {
+			return true
+		}
+	}
+	for _, clientLabel := range rule.ClientLabels {
+		if label != "" && clientLabel == label {
+			return true
+		}
+	}
+	return false
+}
+
+// 与Apollo一致的watchKey格式：appId+cluster+namespace
+func watchKey(appId, cluster, namespaceName string) string {
+	return appId + "+" + cluster + "+" + namespaceName
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json;charset=UTF-8")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,156 @@
+package apollotest
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testAppId = "apollo-client-test"
+
+func TestConfigs(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	releaseKey := server.Publish(testAppId, "default", "application", map[string]string{"timeout": "100"})
+
+	statusCode, body := testGet(t, server.URL+"/configs/apollo-client-test/default/application", "", "")
+	if statusCode != http.StatusOK {
+		t.Fatal(fmt.Sprintf("unexpected status code: %d", statusCode))
+	}
+	var configs struct {
+		Configurations map[string]string `json:"configurations"`
+		ReleaseKey     string            `json:"releaseKey"`
+	}
+	if err := json.Unmarshal(body, &configs); err != nil {
+		t.Fatal(err)
+	}
+	if configs.ReleaseKey != releaseKey || configs.Configurations["timeout"] != "100" {
+		t.Fatal(fmt.Sprintf("unexpected configs: %s", body))
+	}
+
+	if statusCode, _ = testGet(t, server.URL+"/configs/apollo-client-test/default/application?releaseKey="+releaseKey, "", ""); statusCode != http.StatusNotModified {
+		t.Fatal(fmt.Sprintf("same releaseKey should return 304, but: %d", statusCode))
+	}
+	if statusCode, _ = testGet(t, server.URL+"/configs/apollo-client-test/default/haha", "", ""); statusCode != http.StatusNotFound {
+		t.Fatal(fmt.Sprintf("missing namespace should return 404, but: %d", statusCode))
+	}
+	if statusCode, body = testGet(t, server.URL+"/configfiles/json/apollo-client-test/default/application", "", ""); statusCode != http.StatusOK || !strings.Contains(string(body), `"timeout":"100"`) {
+		t.Fatal(fmt.Sprintf("unexpected configfiles response: %d, %s", statusCode, body))
+	}
+	if server.RequestCount("/configs") != 3 || server.RequestCount("/configfiles") != 1 {
+		t.Fatal("RequestCount returns wrong result")
+	}
+}
+
+func TestGrayRelease(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	server.Publish(testAppId, "default", "application", map[string]string{"timeout": "100"})
+	grayReleaseKey := server.PublishGray(testAppId, "default", "application", GrayRule{ClientIps: []string{"10.0.0.1"}, ClientLabels: []string{"canary"}}, map[string]string{"timeout": "200"})
+
+	for _, query := range []string{"?ip=10.0.0.1", "?label=canary"} {
+		_, body := testGet(t, server.URL+"/configs/apollo-client-test/default/application"+query, "", "")
+		if !strings.Contains(string(body), grayReleaseKey) {
+			t.Fatal(fmt.Sprintf("client matching gray rule should get gray release: %s", body))
+		}
+	}
+	if _, body := testGet(t, server.URL+"/configs/apollo-client-test/default/application?ip=10.0.0.2", "", ""); strings.Contains(string(body), grayReleaseKey) {
+		t.Fatal(fmt.Sprintf("client not matching gray rule should get main release: %s", body))
+	}
+
+	server.AbandonGray(testAppId, "default", "application")
+	if _, body := testGet(t, server.URL+"/configs/apollo-client-test/default/application?ip=10.0.0.1", "", ""); strings.Contains(string(body), grayReleaseKey) {
+		t.Fatal(fmt.Sprintf("abandoned gray release should not be returned: %s", body))
+	}
+}
+
+func TestNotifications(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	server.LongPollTimeout = 100 * time.Millisecond
+	server.Publish(testAppId, "default", "application", map[string]string{"timeout": "100"})
+
+	notificationsUrl := func(notificationId int64) string {
+		return fmt.Sprintf(
+			"%s/notifications/v2?appId=%s&cluster=default&notifications=%s",
+			server.URL,
+			testAppId,
+			url.QueryEscape(fmt.Sprintf(`[{"namespaceName":"application","notificationId":%d}]`, notificationId)),
+		)
+	}
+
+	statusCode, body := testGet(t, notificationsUrl(-1), "", "")
+	if statusCode != http.StatusOK || !strings.Contains(string(body), `"notificationId":1`) || !strings.Contains(string(body), "apollo-client-test+default+application") {
+		t.Fatal(fmt.Sprintf("unexpected notifications: %d, %s", statusCode, body))
+	}
+
+	//无变更时挂起后返回304
+	start := time.Now()
+	if statusCode, _ = testGet(t, notificationsUrl(1), "", ""); statusCode != http.StatusNotModified {
+		t.Fatal(fmt.Sprintf("long poll should return 304 when nothing changed, but: %d", statusCode))
+	}
+	if time.Since(start) < 100*time.Millisecond {
+		t.Fatal("long poll should be held until LongPollTimeout")
+	}
+
+	//挂起期间发布会立即返回
+	server.LongPollTimeout = 10 * time.Second
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		server.Publish(testAppId, "default", "application", map[string]string{"timeout": "200"})
+	}()
+	if statusCode, body = testGet(t, notificationsUrl(1), "", ""); statusCode != http.StatusOK || !strings.Contains(string(body), `"notificationId":2`) {
+		t.Fatal(fmt.Sprintf("long poll should return when namespace is published: %d, %s", statusCode, body))
+	}
+}
+
+func TestSignature(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	secret := "4081edabfe4e4ba097cc16defc526c2f"
+	server.SetSecret(testAppId, secret)
+	server.Publish(testAppId, "default", "application", map[string]string{"timeout": "100"})
+
+	requestUri := "/configs/apollo-client-test/default/application?ip=10.0.0.1"
+	if statusCode, _ := testGet(t, server.URL+requestUri, "", ""); statusCode != http.StatusUnauthorized {
+		t.Fatal(fmt.Sprintf("unsigned request should return 401, but: %d", statusCode))
+	}
+	if statusCode, _ := testGet(t, server.URL+requestUri, "wrong-secret", requestUri); statusCode != http.StatusUnauthorized {
+		t.Fatal(fmt.Sprintf("request signed with wrong secret should return 401, but: %d", statusCode))
+	}
+	if statusCode, _ := testGet(t, server.URL+requestUri, secret, requestUri); statusCode != http.StatusOK {
+		t.Fatal(fmt.Sprintf("signed request should return 200, but: %d", statusCode))
+	}
+}
+
+func testGet(t *testing.T, requestUrl, secret, pathWithQuery string) (int, []byte) {
+	req, err := http.NewRequest(http.MethodGet, requestUrl, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret != "" {
+		timestamp := fmt.Sprintf("%d", time.Now().UnixMilli())
+		h := hmac.New(sha1.New, []byte(secret))
+		h.Write([]byte(timestamp + "\n" + pathWithQuery))
+		req.Header.Set("Timestamp", timestamp)
+		req.Header.Set("Authorization", fmt.Sprintf("Apollo %s:%s", testAppId, base64.StdEncoding.EncodeToString(h.Sum(nil))))
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, body
+}
@@ -0,0 +1,221 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"github.com/flylan/apollo-config-lib/request"
+	"math/rand"
+	"net/http"
+	"net/http/httptrace"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	CONFIG_SERVER_URL_SEPARATOR = ","
+	DEFAULT_EJECT_DURATION      = 30 * time.Second
+)
+
+// 负载均衡策略
+type Balancer interface {
+	// 返回本次请求依次尝试的config service地址
+	Order(urls []string) []string
+	// 反馈某个地址的请求结果
+	Feedback(url string, success bool)
+}
+
+// 轮询
+type RoundRobinBalancer struct {
+	counter uint64
+}
+
+// 随机
+type RandomBalancer struct{}
+
+// 一直使用同一个地址，请求失败后切换到下一个可用地址
+type StickyBalancer struct {
+	mu      sync.RWMutex
+	current string
+}
+
+// 被临时剔除的地址
+type ejector struct {
+	mu      sync.Mutex
+	ejected map[string]time.Time
+}
+
+func (b *RoundRobinBalancer) Order(urls []string) []string {
+	if len(urls) == 0 {
+		return urls
+	}
+	offset := int((atomic.AddUint64(&b.counter, 1) - 1) % uint64(len(urls)))
+	return append(append(make([]string, 0, len(urls)), urls[offset:]...), urls[:offset]...)
+}
+
+func (b *RoundRobinBalancer) Feedback(url string, success bool) {}
+
+func (b *RandomBalancer) Order(urls []string) []string {
+	ordered := make([]string, len(urls))
+	for i, j := range rand.Perm(len(urls)) {
+		ordered[i] = urls[j]
+	}
+	return ordered
+}
+
+func (b *RandomBalancer) Feedback(url string, success bool) {}
+
+func (b *StickyBalancer) Order(urls []string) []string {
+	b.mu.RLock()
+	current := b.current
+	b.mu.RUnlock()
+	ordered := make([]string, 0, len(urls))
+	for i, url := range urls {
+		if url == current {
+			ordered = append(ordered, urls[i:]...)
+			return append(ordered, urls[:i]...)
+		}
+	}
+	return append(ordered, urls...)
+}
+
+func (b *StickyBalancer) Feedback(url string, success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if success {
+		b.current = url
+	} else if b.current == url {
+		b.current = ""
+	}
+}
+
+// 剔除地址一段时间
+func (e *ejector) eject(url string, duration time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.ejected == nil {
+		e.ejected = map[string]time.Time{}
+	}
+	e.ejected[url] = time.Now().Add(duration)
+}
+
+// 恢复地址
+func (e *ejector) recover(url string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.ejected, url)
+}
+
+// 过滤掉被剔除的地址，全部被剔除时返回原列表，避免无地址可用
+func (e *ejector) filter(urls []string) []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	now := time.Now()
+	healthy := make([]string, 0, len(urls))
+	for _, url := range urls {
+		if until, ok := e.ejected[url]; ok && now.Before(until) {
+			continue
+		}
+		healthy = append(healthy, url)
+	}
+	if len(healthy) == 0 {
+		return urls
+	}
+	return healthy
+}
+
+// 拆分逗号分隔的config service地址
+func splitConfigServerUrl(configServerUrl string) []string {
+	var urls []string
+	for _, url := range strings.Split(configServerUrl, CONFIG_SERVER_URL_SEPARATOR) {
+		if url = strings.TrimRight(strings.TrimSpace(url), "/"); url != "" {
+			urls = append(urls, url)
+		}
+	}
+	return urls
+}
+
+// 获取所有config service地址，使用服务发现时从meta server获取
+func (c *Client) configServerUrls(ctx context.Context) ([]string, error) {
+	if c.Discovery != nil {
+		return c.Discovery.ConfigServerUrlsWithContext(ctx, c.AppId, c.outboundIP())
+	}
+	urls := splitConfigServerUrl(c.ConfigServerUrl)
+	if len(urls) == 0 {
+		return nil, errors.New("ConfigServerUrl is empty")
+	}
+	return urls, nil
+}
+
+// 获取负载均衡策略，默认随机
+func (c *Client) balancer() Balancer {
+	if c.Balancer == nil {
+		return &RandomBalancer{}
+	}
+	return c.Balancer
+}
+
+// 发起请求，按客户端的重试策略对失败的请求进行重试，longPoll表示长轮询请求
+func (c *Client) sendGetRequest(ctx context.Context, buildUrl func(configServerUrl string) string, timeout time.Duration, longPoll bool, info *request.Info) (*request.Info, error) {
+	for attempt := 1; ; attempt++ {
+		var err error
+		info, err = c.sendGetRequestWithFailover(ctx, buildUrl, timeout, longPoll, info)
+		if ctx.Err() != nil {
+			return info, ctx.Err()
+		}
+		if c.RetryPolicy == nil || attempt >= c.RetryPolicy.MaxAttempts || !c.RetryPolicy.IsRetryable(info, err) {
+			return info, err
+		}
+		if err = sleepWithContext(ctx, c.RetryPolicy.Backoff(attempt)); err != nil {
+			return info, err
+		}
+	}
+}
+
+// 按负载均衡策略依次请求config service，连接失败或返回5xx时剔除该地址并尝试下一个，ctx取消时立即返回
+// 已建立连接的长轮询请求超时不代表config service不可用（服务端会挂起请求），直接返回错误，不剔除该地址
+func (c *Client) sendGetRequestWithFailover(ctx context.Context, buildUrl func(configServerUrl string) string, timeout time.Duration, longPoll bool, info *request.Info) (*request.Info, error) {
+	urls, err := c.configServerUrls(ctx)
+	if err != nil {
+		return info, err
+	}
+	ejectDuration := c.EjectDuration
+	if ejectDuration <= 0 {
+		ejectDuration = DEFAULT_EJECT_DURATION
+	}
+
+	balancer := c.balancer()
+	for _, configServerUrl := range balancer.Order(c.ejector.filter(urls)) {
+		if ctx.Err() != nil {
+			return info, ctx.Err()
+		}
+		requestCtx, connected := ctx, (*int32)(nil)
+		if longPoll {
+			requestCtx, connected = traceConnected(ctx)
+		}
+		info, err = c.sendGetRequestWithSecrets(requestCtx, buildUrl(configServerUrl), timeout, info)
+		if ctx.Err() != nil {
+			return info, ctx.Err()
+		}
+		if err == nil && info.StatusCode < http.StatusInternalServerError {
+			c.ejector.recover(configServerUrl)
+			balancer.Feedback(configServerUrl, true)
+			return info, nil
+		}
+		if connected != nil && atomic.LoadInt32(connected) == 1 && errors.Is(err, context.DeadlineExceeded) {
+			return info, err
+		}
+		c.ejector.eject(configServerUrl, ejectDuration)
+		balancer.Feedback(configServerUrl, false)
+	}
+	return info, err
+}
+
+// 记录请求是否已经建立连接，建立连接前超时（如dial i/o timeout）说明地址不可用
+func traceConnected(ctx context.Context) (context.Context, *int32) {
+	connected := new(int32)
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GotConn: func(httptrace.GotConnInfo) { atomic.StoreInt32(connected, 1) },
+	}), connected
+}
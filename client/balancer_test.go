@@ -0,0 +1,166 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/flylan/apollo-config-lib/apollotest"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRoundRobinBalancer(t *testing.T) {
+	urls := []string{"a", "b", "c"}
+	balancer := &RoundRobinBalancer{}
+	for _, expect := range [][]string{{"a", "b", "c"}, {"b", "c", "a"}, {"c", "a", "b"}, {"a", "b", "c"}} {
+		if ordered := balancer.Order(urls); !reflect.DeepEqual(ordered, expect) {
+			t.Fatal(fmt.Sprintf("RoundRobinBalancer error, expect: %v, but: %v", expect, ordered))
+		}
+	}
+}
+
+func TestRandomBalancer(t *testing.T) {
+	urls := []string{"a", "b", "c"}
+	ordered := (&RandomBalancer{}).Order(urls)
+	sort.Strings(ordered)
+	if !reflect.DeepEqual(ordered, urls) {
+		t.Fatal(fmt.Sprintf("RandomBalancer should return all urls, but: %v", ordered))
+	}
+}
+
+func TestStickyBalancer(t *testing.T) {
+	urls := []string{"a", "b", "c"}
+	balancer := &StickyBalancer{}
+	if ordered := balancer.Order(urls); !reflect.DeepEqual(ordered, urls) {
+		t.Fatal(fmt.Sprintf("StickyBalancer error, but: %v", ordered))
+	}
+	balancer.Feedback("b", true)
+	if ordered := balancer.Order(urls); !reflect.DeepEqual(ordered, []string{"b", "c", "a"}) {
+		t.Fatal(fmt.Sprintf("StickyBalancer should stick to b, but: %v", ordered))
+	}
+	balancer.Feedback("b", false)
+	balancer.Feedback("c", true)
+	if ordered := balancer.Order(urls); !reflect.DeepEqual(ordered, []string{"c", "a", "b"}) {
+		t.Fatal(fmt.Sprintf("StickyBalancer should fail over to c, but: %v", ordered))
+	}
+}
+
+func TestEjector(t *testing.T) {
+	e := &ejector{}
+	urls := []string{"a", "b"}
+	e.eject("a", time.Hour)
+	if healthy := e.filter(urls); !reflect.DeepEqual(healthy, []string{"b"}) {
+		t.Fatal(fmt.Sprintf("ejected url should be filtered, but: %v", healthy))
+	}
+	e.eject("b", time.Hour)
+	if healthy := e.filter(urls); !reflect.DeepEqual(healthy, urls) {
+		t.Fatal(fmt.Sprintf("all urls should be returned when all ejected, but: %v", healthy))
+	}
+	e.recover("a")
+	e.eject("b", -time.Second)
+	if healthy := e.filter(urls); !reflect.DeepEqual(healthy, urls) {
+		t.Fatal(fmt.Sprintf("recovered or expired urls should be returned, but: %v", healthy))
+	}
+}
+
+func TestSendGetRequestFailover(t *testing.T) {
+	var downRequests int32
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&downRequests, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer down.Close()
+	up := apollotest.NewServer()
+	defer up.Close()
+	up.Publish(testAppId, DEFAULT_CLUSTER_NAME, "application", Configurations{"timeout": "100"})
+
+	client, err := NewClient(down.URL+","+up.URL, testAppId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Balancer = &StickyBalancer{}
+	for i := 0; i < 3; i++ {
+		configs, _, err := client.Configs("application").Get()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if configs.Configurations["timeout"] != "100" {
+			t.Fatal(fmt.Sprintf("unexpected configs: %v", configs))
+		}
+	}
+	if atomic.LoadInt32(&downRequests) != 1 {
+		t.Fatal(fmt.Sprintf("unhealthy url should be ejected after failure, requests: %d", downRequests))
+	}
+
+	//所有地址都不可用时返回最后一次的结果
+	client.ConfigServerUrl = down.URL
+	if _, info, err := client.Configs("application").Get(); err == nil || info.StatusCode != http.StatusBadGateway {
+		t.Fatal(fmt.Sprintf("Get should return error when all urls are down, info: %v", info))
+	}
+}
+
+func TestLongPollTimeoutNotEjected(t *testing.T) {
+	first := apollotest.NewServer()
+	defer first.Close()
+	second := apollotest.NewServer()
+	defer second.Close()
+	for _, server := range []*apollotest.Server{first, second} {
+		server.Publish(testAppId, DEFAULT_CLUSTER_NAME, "application", Configurations{"timeout": "100"})
+		server.SetNotificationId(testAppId, DEFAULT_CLUSTER_NAME, "application", 100)
+	}
+
+	client, err := NewClient(first.URL+","+second.URL, testAppId, WithRequestTimeout(0, 100*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Balancer = &StickyBalancer{}
+	for i := 0; i < 2; i++ {
+		_, _, err = client.Notifications(map[string]int64{"application": 100}).Get()
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatal(fmt.Sprintf("long poll should time out, err: %v", err))
+		}
+	}
+	if requests := first.RequestCount("/notifications/v2") + second.RequestCount("/notifications/v2"); requests != 2 {
+		t.Fatal(fmt.Sprintf("long poll timeout should not fail over to next url, requests: %d", requests))
+	}
+	if healthy := client.ejector.filter([]string{first.URL, second.URL}); len(healthy) != 2 {
+		t.Fatal(fmt.Sprintf("long poll timeout should not eject url, healthy: %v", healthy))
+	}
+}
+
+func TestLongPollDialTimeoutEjected(t *testing.T) {
+	server := apollotest.NewServer()
+	defer server.Close()
+	server.Publish(testAppId, DEFAULT_CLUSTER_NAME, "application", Configurations{"timeout": "100"})
+
+	//第一个地址永远无法完成建立连接
+	unreachable := "10.255.255.1:8080"
+	transport := &http.Transport{DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+		if address == unreachable {
+			<-ctx.Done()
+			return nil, &net.OpError{Op: "dial", Net: network, Err: ctx.Err()}
+		}
+		return (&net.Dialer{}).DialContext(ctx, network, address)
+	}}
+	client, err := NewClient("http://"+unreachable+","+server.URL, testAppId, WithTransport(transport), WithRequestTimeout(0, 200*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Balancer = &StickyBalancer{}
+	notifications, _, err := client.Notifications("application").Get()
+	if err != nil {
+		t.Fatal(fmt.Sprintf("long poll should fail over when dial times out, err: %v", err))
+	}
+	if len(*notifications) != 1 {
+		t.Fatal(fmt.Sprintf("unexpected notifications: %v", notifications))
+	}
+	if healthy := client.ejector.filter([]string{"http://" + unreachable, server.URL}); len(healthy) != 1 || healthy[0] != server.URL {
+		t.Fatal(fmt.Sprintf("unreachable url should be ejected, healthy: %v", healthy))
+	}
+}
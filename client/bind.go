@@ -0,0 +1,105 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// 绑定了namespace配置的结构体，namespace变更时重新绑定并原子替换，读取无需加锁
+type Binding[T any] struct {
+	client         *Client
+	namespaceName  string
+	value          atomic.Value
+	reloadMu       sync.Mutex
+	mu             sync.RWMutex
+	updateHandlers []func(oldValue, newValue T)
+	errorHandlers  []func(err error)
+	unregister     func()
+}
+
+// 将namespace配置绑定到结构体T（规则同 Unmarshal），之后namespace每次变更都会重新绑定
+//
+// 配置仓库中还没有该namespace时会先从服务端拉取一次（失败时读取本地缓存），
+// 变更需要通过 Watcher 监听该namespace才会推送
+func Bind[T any](c *Client, namespaceName string) (*Binding[T], error) {
+	return BindWithContext[T](context.Background(), c, namespaceName)
+}
+
+func BindWithContext[T any](ctx context.Context, c *Client, namespaceName string) (*Binding[T], error) {
+	if _, ok := c.Repository().Get(namespaceName); !ok {
+		configs, _, err := c.Configs(namespaceName).GetWithContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		c.applyConfigs(configs)
+	}
+
+	b := &Binding[T]{client: c, namespaceName: namespaceName}
+	value, err := b.unmarshal()
+	if err != nil {
+		return nil, err
+	}
+	b.value.Store(value)
+	b.unregister = c.OnChange(func(event *ChangeEvent) {
+		if event.NamespaceName == namespaceName {
+			b.reload()
+		}
+	})
+	return b, nil
+}
+
+// 获取当前绑定的结构体快照
+func (b *Binding[T]) Load() T {
+	return *b.value.Load().(*T)
+}
+
+// 停止跟随namespace变更重新绑定，之后 Load 返回最后一次绑定的结构体
+func (b *Binding[T]) Close() {
+	b.unregister()
+}
+
+// 注册更新回调，重新绑定成功后触发
+func (b *Binding[T]) OnUpdate(handler func(oldValue, newValue T)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.updateHandlers = append(b.updateHandlers, handler)
+}
+
+// 注册错误回调，重新绑定失败时触发，此时会继续使用上一次绑定成功的结构体
+func (b *Binding[T]) OnError(handler func(err error)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.errorHandlers = append(b.errorHandlers, handler)
+}
+
+// 从配置仓库重新绑定，加锁保证并发变更时按顺序替换
+func (b *Binding[T]) reload() {
+	b.reloadMu.Lock()
+	defer b.reloadMu.Unlock()
+	b.mu.RLock()
+	updateHandlers, errorHandlers := b.updateHandlers, b.errorHandlers
+	b.mu.RUnlock()
+
+	value, err := b.unmarshal()
+	if err != nil {
+		b.client.logf("Apollo bind namespace %s error: %v", b.namespaceName, err)
+		for _, handler := range errorHandlers {
+			handler(err)
+		}
+		return
+	}
+	oldValue := b.value.Load().(*T)
+	b.value.Store(value)
+	for _, handler := range updateHandlers {
+		handler(*oldValue, *value)
+	}
+}
+
+func (b *Binding[T]) unmarshal() (*T, error) {
+	value := new(T)
+	if err := b.client.Config(b.namespaceName).Unmarshal(value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
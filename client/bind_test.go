@@ -0,0 +1,114 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/flylan/apollo-config-lib/apollotest"
+	"testing"
+	"time"
+)
+
+type testBindConfig struct {
+	Timeout time.Duration `apollo:"timeout" required:"true"`
+	Hosts   []string      `apollo:"hosts"`
+}
+
+func TestBind(t *testing.T) {
+	server := apollotest.NewServer()
+	defer server.Close()
+	server.Publish(testAppId, DEFAULT_CLUSTER_NAME, "application", Configurations{"timeout": "100", "hosts": "a,b"})
+
+	client, err := NewClient(server.URL, testAppId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	binding, err := Bind[testBindConfig](client, "application")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config := binding.Load(); config.Timeout != 100*time.Millisecond || len(config.Hosts) != 2 {
+		t.Fatal(fmt.Sprintf("unexpected bound config: %+v", config))
+	}
+
+	type update struct{ oldValue, newValue testBindConfig }
+	updates := make(chan update, 10)
+	errs := make(chan error, 10)
+	binding.OnUpdate(func(oldValue, newValue testBindConfig) { updates <- update{oldValue, newValue} })
+	binding.OnError(func(err error) { errs <- err })
+
+	watcher := client.Watcher("application")
+	if err = watcher.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Stop()
+
+	server.Publish(testAppId, DEFAULT_CLUSTER_NAME, "application", Configurations{"timeout": "1s", "hosts": "c"})
+	select {
+	case u := <-updates:
+		if u.oldValue.Timeout != 100*time.Millisecond || u.newValue.Timeout != time.Second || len(u.newValue.Hosts) != 1 {
+			t.Fatal(fmt.Sprintf("unexpected update: %+v", u))
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for binding update")
+	}
+	if binding.Load().Timeout != time.Second {
+		t.Fatal("Load should return the new snapshot after update")
+	}
+
+	server.Publish(testAppId, DEFAULT_CLUSTER_NAME, "application", Configurations{"hosts": "d"})
+	select {
+	case err = <-errs:
+		if !errors.Is(err, ErrRequired) {
+			t.Fatal(fmt.Sprintf("unexpected error: %v", err))
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for binding error")
+	}
+	if config := binding.Load(); config.Timeout != time.Second || config.Hosts[0] != "c" {
+		t.Fatal(fmt.Sprintf("Load should keep the last valid snapshot on error: %+v", config))
+	}
+}
+
+func TestBindingClose(t *testing.T) {
+	server := apollotest.NewServer()
+	defer server.Close()
+	server.Publish(testAppId, DEFAULT_CLUSTER_NAME, "application", Configurations{"timeout": "100"})
+
+	client, err := NewClient(server.URL, testAppId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	binding, err := Bind[testBindConfig](client, "application")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(client.changes.listeners) != 1 {
+		t.Fatal(fmt.Sprintf("Bind should register one listener, got: %d", len(client.changes.listeners)))
+	}
+	binding.Close()
+	if len(client.changes.listeners) != 0 {
+		t.Fatal(fmt.Sprintf("Close should unregister the listener, got: %d", len(client.changes.listeners)))
+	}
+	client.applyConfigs(&Configs{NamespaceName: "application", Configurations: Configurations{"timeout": "1s"}})
+	if binding.Load().Timeout != 100*time.Millisecond {
+		t.Fatal("closed binding should keep the last snapshot")
+	}
+}
+
+func TestBindError(t *testing.T) {
+	server := apollotest.NewServer()
+	defer server.Close()
+	server.Publish(testAppId, DEFAULT_CLUSTER_NAME, "application", Configurations{"hosts": "a"})
+
+	client, err := NewClient(server.URL, testAppId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = Bind[testBindConfig](client, "application"); !errors.Is(err, ErrRequired) {
+		t.Fatal(fmt.Sprintf("Bind should return unmarshal error, err: %v", err))
+	}
+	if _, err = Bind[testBindConfig](client, "not-exists"); err == nil {
+		t.Fatal("Bind should return error when namespace does not exist")
+	}
+}
@@ -0,0 +1,102 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	CACHE_FILE_SEPARATOR = "+"
+	CACHE_FILE_SUFFIX    = ".json"
+)
+
+// 配置本地缓存，远程拉取失败时作为兜底
+type Cache interface {
+	Load(appId, cluster, namespaceName string) (*Configs, error)
+	Save(appId, cluster, namespaceName string, configs *Configs) error
+}
+
+// 基于本地文件的配置缓存，文件名格式与Apollo Java客户端一致：appId+cluster+namespace
+type FileCache struct {
+	Dir string
+}
+
+// 构建一个本地文件缓存实例
+func NewFileCache(dir string) *FileCache {
+	return &FileCache{Dir: dir}
+}
+
+// 缓存文件路径
+func (fc *FileCache) path(appId, cluster, namespaceName string) string {
+	name := appId + CACHE_FILE_SEPARATOR + cluster + CACHE_FILE_SEPARATOR + namespaceName + CACHE_FILE_SUFFIX
+	return filepath.Join(fc.Dir, filepath.Base(name))
+}
+
+// 从本地文件读取配置
+func (fc *FileCache) Load(appId, cluster, namespaceName string) (*Configs, error) {
+	content, err := os.ReadFile(fc.path(appId, cluster, namespaceName))
+	if err != nil {
+		return nil, err
+	}
+	configs := &Configs{}
+	if err = json.Unmarshal(content, configs); err != nil {
+		return nil, err
+	}
+	return configs, nil
+}
+
+// 保存配置到本地文件，先写临时文件再重命名，避免进程中断时留下不完整的文件
+func (fc *FileCache) Save(appId, cluster, namespaceName string, configs *Configs) error {
+	if configs == nil {
+		return errors.New("Configs is nil")
+	}
+	content, err := json.Marshal(configs)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(fc.Dir, 0755); err != nil {
+		return err
+	}
+
+	path := fc.path(appId, cluster, namespaceName)
+	tmp, err := os.CreateTemp(fc.Dir, filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err = tmp.Write(content); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// 从本地缓存读取namespace配置
+func (c *Client) loadCache(namespaceName string) (*Configs, error) {
+	if c.Cache == nil {
+		return nil, errors.New("Cache is not configured")
+	}
+	configs, err := c.Cache.Load(c.AppId, c.ClusterName, namespaceName)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to load namespace %s from local cache: %w", namespaceName, err)
+	}
+	configs.FromCache = true
+	return configs, nil
+}
+
+// 保存namespace配置到本地缓存，未配置缓存时忽略
+func (c *Client) saveCache(namespaceName string, configs *Configs) error {
+	if c.Cache == nil {
+		return nil
+	}
+	return c.Cache.Save(c.AppId, c.ClusterName, namespaceName, configs)
+}
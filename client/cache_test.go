@@ -0,0 +1,153 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/flylan/apollo-config-lib/apollotest"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFileCache(t *testing.T) {
+	cache := NewFileCache(filepath.Join(t.TempDir(), "apollo"))
+	if _, err := cache.Load("apollo-client-test", "default", "application"); err == nil {
+		t.Fatal("Load should return error when cache file not exists")
+	}
+
+	configs := &Configs{
+		AppId:          "apollo-client-test",
+		Cluster:        "default",
+		NamespaceName:  "application",
+		Configurations: Configurations{"timeout": "100"},
+		ReleaseKey:     "20240801-release",
+	}
+	if err := cache.Save("apollo-client-test", "default", "application", configs); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := cache.Load("apollo-client-test", "default", "application")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.ReleaseKey != configs.ReleaseKey || loaded.Configurations["timeout"] != "100" {
+		t.Fatal(fmt.Sprintf("unexpected cached configs: %v", loaded))
+	}
+	if _, err = cache.Load("apollo-client-test", "other", "application"); err == nil {
+		t.Fatal("Load should distinguish cluster")
+	}
+}
+
+func TestConfigsGetFallbackToCache(t *testing.T) {
+	var down int32
+	fake := apollotest.NewServer()
+	defer fake.Close()
+	releaseKey := fake.Publish(testAppId, DEFAULT_CLUSTER_NAME, "application", Configurations{"timeout": "100"})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&down) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		fake.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, testAppId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Cache = NewFileCache(t.TempDir())
+
+	configs, _, err := client.Configs("application").Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if configs.FromCache {
+		t.Fatal("configs should come from server")
+	}
+
+	atomic.StoreInt32(&down, 1)
+	configs, info, err := client.Configs("application").Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !configs.FromCache || configs.ReleaseKey != releaseKey || configs.Configurations["timeout"] != "100" {
+		t.Fatal(fmt.Sprintf("configs should come from local cache: %v", configs))
+	}
+	if info.StatusCode != http.StatusInternalServerError {
+		t.Fatal(fmt.Sprintf("info should keep the remote status code: %d", info.StatusCode))
+	}
+
+	if _, _, err = client.Configs("haha").Get(); err == nil {
+		t.Fatal("Get should return remote error when namespace is not cached")
+	}
+}
+
+func TestConfigsGetNotFallbackToCache(t *testing.T) {
+	server := apollotest.NewServer()
+	defer server.Close()
+	server.Publish(testAppId, DEFAULT_CLUSTER_NAME, "application", Configurations{"timeout": "100"})
+
+	client, err := NewClient(server.URL, testAppId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Cache = NewFileCache(t.TempDir())
+	if _, _, err = client.Configs("application").Get(); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if configs, _, err := client.Configs("application").GetWithContext(ctx); !errors.Is(err, context.Canceled) || configs != nil {
+		t.Fatal(fmt.Sprintf("cancelled ctx should not fallback to local cache, configs: %v, err: %v", configs, err))
+	}
+
+	server.Delete(testAppId, DEFAULT_CLUSTER_NAME, "application")
+	if configs, _, err := client.Configs("application").Get(); !errors.Is(err, ErrNamespaceNotFound) || configs != nil {
+		t.Fatal(fmt.Sprintf("404 should not fallback to local cache, configs: %v, err: %v", configs, err))
+	}
+}
+
+func TestWatcherLoadCache(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "apollo-client-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Cache = NewFileCache(t.TempDir())
+	err = client.Cache.Save(client.AppId, client.ClusterName, "application", &Configs{
+		NamespaceName:  "application",
+		Configurations: Configurations{"timeout": "100"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := make(chan *ChangeEvent, 1)
+	client.OnChange(func(event *ChangeEvent) { events <- event })
+	watcher := client.Watcher("application")
+	watcher.RetryInterval = 10 * time.Millisecond
+	if err = watcher.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Stop()
+
+	event := waitChangeEvent(t, events)
+	if event.Added["timeout"] != "100" {
+		t.Fatal(fmt.Sprintf("unexpected change event: %v", event))
+	}
+	if client.Config("application").GetIntOrDefault("timeout", 0) != 100 {
+		t.Fatal("Repository should be loaded from local cache")
+	}
+	if watcher.NotificationId("application") != DEFAULT_NOTIFICATION_ID {
+		t.Fatal("NotificationId should not advance when configs come from local cache")
+	}
+}
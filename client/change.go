@@ -0,0 +1,112 @@
+package client
+
+import "sync"
+
+type ValueChange struct {
+	OldValue string
+	NewValue string
+}
+
+type ChangeEvent struct {
+	NamespaceName string
+	ReleaseKey    string
+	Added         map[string]string
+	Modified      map[string]ValueChange
+	Deleted       map[string]string
+}
+
+type ChangeListener func(event *ChangeEvent)
+
+type changeNotifier struct {
+	mu        sync.RWMutex
+	nextId    uint64
+	listeners []registeredListener
+}
+
+type registeredListener struct {
+	id       uint64
+	listener ChangeListener
+}
+
+// 判断是否没有任何key发生变更
+func (e *ChangeEvent) IsEmpty() bool {
+	return len(e.Added) == 0 && len(e.Modified) == 0 && len(e.Deleted) == 0
+}
+
+// 判断指定key是否发生变更（新增、修改或删除）
+func (e *ChangeEvent) IsChanged(key string) bool {
+	if _, ok := e.Added[key]; ok {
+		return true
+	}
+	if _, ok := e.Modified[key]; ok {
+		return true
+	}
+	_, ok := e.Deleted[key]
+	return ok
+}
+
+// 对比新旧配置，计算出每个key的变更
+func diffConfigurations(namespaceName string, oldConfigurations, newConfigurations Configurations) *ChangeEvent {
+	event := &ChangeEvent{
+		NamespaceName: namespaceName,
+		Added:         map[string]string{},
+		Modified:      map[string]ValueChange{},
+		Deleted:       map[string]string{},
+	}
+	for key, newValue := range newConfigurations {
+		oldValue, ok := oldConfigurations[key]
+		if !ok {
+			event.Added[key] = newValue
+		} else if oldValue != newValue {
+			event.Modified[key] = ValueChange{OldValue: oldValue, NewValue: newValue}
+		}
+	}
+	for key, oldValue := range oldConfigurations {
+		if _, ok := newConfigurations[key]; !ok {
+			event.Deleted[key] = oldValue
+		}
+	}
+	return event
+}
+
+// 注册配置变更监听，namespace配置有key变更时触发，返回的函数用于取消监听（可重复调用）
+func (c *Client) OnChange(listener ChangeListener) func() {
+	c.changes.mu.Lock()
+	defer c.changes.mu.Unlock()
+	c.changes.nextId++
+	id := c.changes.nextId
+	c.changes.listeners = append(c.changes.listeners, registeredListener{id: id, listener: listener})
+	return func() { c.changes.remove(id) }
+}
+
+// 移除监听，重新分配切片，避免影响正在通知的监听列表
+func (n *changeNotifier) remove(id uint64) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	listeners := make([]registeredListener, 0, len(n.listeners))
+	for _, registered := range n.listeners {
+		if registered.id != id {
+			listeners = append(listeners, registered)
+		}
+	}
+	n.listeners = listeners
+}
+
+// 保存namespace最新配置到仓库，并将与上一次配置的差异通知给监听者
+func (c *Client) applyConfigs(configs *Configs) *ChangeEvent {
+	event, err := c.Repository().set(configs)
+	if err != nil {
+		c.logf("Apollo parse namespace %s error: %v", configs.NamespaceName, err)
+	}
+	if event.IsEmpty() {
+		return event
+	}
+
+	c.changes.mu.RLock()
+	listeners := c.changes.listeners
+	c.changes.mu.RUnlock()
+	for _, registered := range listeners {
+		registered.listener(event)
+	}
+	return event
+}
@@ -0,0 +1,96 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"github.com/flylan/apollo-config-lib/apollotest"
+	"testing"
+	"time"
+)
+
+func TestDiffConfigurations(t *testing.T) {
+	event := diffConfigurations(
+		"application",
+		Configurations{"a": "1", "b": "2", "c": "3"},
+		Configurations{"a": "1", "b": "20", "d": "4"},
+	)
+	if event.NamespaceName != "application" {
+		t.Fatal(fmt.Sprintf("unexpected namespaceName: %s", event.NamespaceName))
+	}
+	if len(event.Added) != 1 || event.Added["d"] != "4" {
+		t.Fatal(fmt.Sprintf("unexpected added: %v", event.Added))
+	}
+	if len(event.Modified) != 1 || event.Modified["b"] != (ValueChange{OldValue: "2", NewValue: "20"}) {
+		t.Fatal(fmt.Sprintf("unexpected modified: %v", event.Modified))
+	}
+	if len(event.Deleted) != 1 || event.Deleted["c"] != "3" {
+		t.Fatal(fmt.Sprintf("unexpected deleted: %v", event.Deleted))
+	}
+	if event.IsChanged("a") || !event.IsChanged("b") || !event.IsChanged("c") || !event.IsChanged("d") {
+		t.Fatal("IsChanged returns wrong result")
+	}
+	if !diffConfigurations("application", Configurations{"a": "1"}, Configurations{"a": "1"}).IsEmpty() {
+		t.Fatal("diff of same configurations should be empty")
+	}
+}
+
+func TestOnChange(t *testing.T) {
+	server := apollotest.NewServer()
+	defer server.Close()
+	server.Publish(testAppId, DEFAULT_CLUSTER_NAME, "application", Configurations{"timeout": "100", "retry": "3"})
+
+	client, err := NewClient(server.URL, testAppId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := make(chan *ChangeEvent, 10)
+	client.OnChange(func(event *ChangeEvent) { events <- event })
+
+	watcher := client.Watcher("application")
+	if err = watcher.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Stop()
+
+	event := waitChangeEvent(t, events)
+	if len(event.Added) != 2 || len(event.Modified) != 0 || len(event.Deleted) != 0 {
+		t.Fatal(fmt.Sprintf("first load should add all keys: %v", event))
+	}
+
+	releaseKey := server.Publish(testAppId, DEFAULT_CLUSTER_NAME, "application", Configurations{"timeout": "200", "enable": "true"})
+	event = waitChangeEvent(t, events)
+	if event.ReleaseKey != releaseKey {
+		t.Fatal(fmt.Sprintf("unexpected releaseKey: %s", event.ReleaseKey))
+	}
+	if event.Added["enable"] != "true" || event.Modified["timeout"].NewValue != "200" || event.Deleted["retry"] != "3" {
+		t.Fatal(fmt.Sprintf("unexpected change event: %v", event))
+	}
+}
+
+func TestOnChangeUnregister(t *testing.T) {
+	client, err := NewClient("http://127.0.0.1:8080", testAppId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var first, second int
+	unregister := client.OnChange(func(event *ChangeEvent) { first++ })
+	client.OnChange(func(event *ChangeEvent) { second++ })
+
+	client.applyConfigs(&Configs{NamespaceName: "application", Configurations: Configurations{"timeout": "100"}})
+	unregister()
+	unregister()
+	client.applyConfigs(&Configs{NamespaceName: "application", Configurations: Configurations{"timeout": "200"}})
+	if first != 1 || second != 2 || len(client.changes.listeners) != 1 {
+		t.Fatal(fmt.Sprintf("unregistered listener should not be notified, first: %d, second: %d", first, second))
+	}
+}
+
+func waitChangeEvent(t *testing.T, events chan *ChangeEvent) *ChangeEvent {
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for change event")
+	}
+	return nil
+}
@@ -3,12 +3,21 @@ package client
 import (
 	"errors"
 	"fmt"
+	"github.com/flylan/apollo-config-lib/request"
 	"github.com/flylan/apollo-config-lib/utils"
 	"net"
+	"net/http"
+	"sync"
 	"time"
 )
 
-const DEFAULT_CLUSTER_NAME = "default"
+const (
+	DEFAULT_CLUSTER_NAME = "default"
+
+	DEFAULT_GET_CONFIGS_TIMEOUT = 10 * time.Second
+	//config service长轮询最多挂起60秒，超时时间需要大于挂起时间，与Java客户端一致取90秒
+	DEFAULT_GET_NOTIFICATIONS_TIMEOUT = 90 * time.Second
+)
 
 type RequestTimeout struct {
 	GetConfigs       time.Duration
@@ -20,11 +29,30 @@ type Client struct {
 	AppId           string
 	ClusterName     string
 	Secret          string
+	Label           string
+	Ip              net.IP
 	RequestTimeout  RequestTimeout
+	HttpClient      *http.Client
+	TLSConfig       *request.TLSConfig
+	Logger          Logger
+	Cache           Cache
+	Discovery       *ServiceDiscovery
+	Balancer        Balancer
+	EjectDuration   time.Duration
+	RetryPolicy     *RetryPolicy
+	Decryptor       Decryptor
+	Signer          request.Signer
+	ejector         ejector
+	probeTimeout    time.Duration
 	address         string
+	secrets         secretList
+	changes         changeNotifier
+	repository      *Repository
+	repositoryOnce  sync.Once
 }
 
-func NewClient(configServerUrl, appId string) (*Client, error) {
+// 构建客户端，默认不检测config service是否可以访问，可以通过WithReachabilityCheck开启
+func NewClient(configServerUrl, appId string, opts ...Option) (*Client, error) {
 	if configServerUrl == "" {
 		return nil, errors.New("ConfigServerUrl is empty")
 	}
@@ -32,27 +60,72 @@ func NewClient(configServerUrl, appId string) (*Client, error) {
 		return nil, errors.New("AppId is empty")
 	}
 
-	//解析url
-	urlInfo, err := utils.ParseUrl(configServerUrl)
-	if err != nil {
-		return nil, err
+	//解析url，支持逗号分隔的多个地址
+	var urlInfos []*utils.UrlInfo
+	for _, serverUrl := range splitConfigServerUrl(configServerUrl) {
+		urlInfo, err := utils.ParseUrl(serverUrl)
+		if err != nil {
+			return nil, err
+		}
+		urlInfos = append(urlInfos, urlInfo)
 	}
-
-	//检测主机端口是否可以访问
-	conn, err := net.DialTimeout(utils.NETWORK_TCP, urlInfo.Address, 3*time.Second)
-	if err != nil {
-		return nil, fmt.Errorf("Port %s on %s is closed or not reachable", urlInfo.Port, urlInfo.Host)
+	if len(urlInfos) == 0 {
+		return nil, errors.New("ConfigServerUrl is empty")
 	}
-	defer func() { _ = conn.Close() }()
 
-	return &Client{
+	c := &Client{
 		ConfigServerUrl: configServerUrl,
 		AppId:           appId,
 		ClusterName:     DEFAULT_CLUSTER_NAME,
-		address:         urlInfo.Address,
+		address:         urlInfos[0].Address,
 		RequestTimeout: RequestTimeout{
-			GetConfigs:       10 * time.Second,
-			GetNotifications: 60 * time.Second,
+			GetConfigs:       DEFAULT_GET_CONFIGS_TIMEOUT,
+			GetNotifications: DEFAULT_GET_NOTIFICATIONS_TIMEOUT,
 		},
-	}, nil
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	//配置了TLS时基于TLS配置构建http.Client
+	if c.TLSConfig != nil {
+		if c.HttpClient != nil {
+			return nil, errors.New("TLSConfig can not be used together with HttpClient or Transport")
+		}
+		transport, err := request.NewTLSTransport(c.TLSConfig)
+		if err != nil {
+			return nil, err
+		}
+		c.HttpClient = request.NewHttpClient(transport)
+	}
+
+	//检测主机端口是否可以访问，有一个地址可以访问即可
+	if c.probeTimeout > 0 {
+		if err := probe(urlInfos, c.probeTimeout); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// 检测主机端口是否可以访问
+func probe(urlInfos []*utils.UrlInfo, timeout time.Duration) error {
+	var err error
+	for _, urlInfo := range urlInfos {
+		var conn net.Conn
+		conn, err = net.DialTimeout(utils.NETWORK_TCP, urlInfo.Address, timeout)
+		if err == nil {
+			_ = conn.Close()
+			return nil
+		}
+		err = fmt.Errorf("Port %s on %s is closed or not reachable", urlInfo.Port, urlInfo.Host)
+	}
+	return err
+}
+
+// 输出日志，未配置Logger时忽略
+func (c *Client) logf(format string, v ...interface{}) {
+	if c.Logger != nil {
+		c.Logger.Printf(format, v...)
+	}
 }
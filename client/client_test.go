@@ -1,13 +1,15 @@
 package client
 
 import (
-	"sync"
+	"github.com/flylan/apollo-config-lib/apollotest"
+	"net"
 	"testing"
+	"time"
 )
 
-var (
-	client   *Client
-	syncOnce sync.Once
+const (
+	testAppId  = "apollo-client-test"
+	testSecret = "4081edabfe4e4ba097cc16defc526c2f"
 )
 
 func TestNewClient(t *testing.T) {
@@ -30,24 +32,48 @@ func TestNewClient(t *testing.T) {
 	if err == nil {
 		t.Fatal("NewClient should return error when url is invalid")
 	}
-	_, err = NewClient("http://81.68.181.139:50080", "apollo-client-test")
+	_, err = NewClient("http://"+testClosedAddress(t), "apollo-client-test", WithReachabilityCheck(3*time.Second))
 	if err == nil {
 		t.Fatal("NewClient should return error when port is not reachable")
 	}
 }
 
+// 启动测试用的config service，并发布测试用例需要的namespace，测试结束时自动关闭
+func testNewServer(t *testing.T) *apollotest.Server {
+	server := apollotest.NewServer()
+	t.Cleanup(server.Close)
+	server.SetSecret(testAppId, testSecret)
+	namespaces := map[string]int64{
+		"application":      100,
+		"haha":             200,
+		"TEAM.test_case_1": 300,
+		"test_case_2":      400,
+		"test_case_3":      500,
+		"TEAM.test_case_4": 600,
+	}
+	for namespaceName, notificationId := range namespaces {
+		server.Publish(testAppId, DEFAULT_CLUSTER_NAME, namespaceName, map[string]string{"namespace": namespaceName})
+		server.SetNotificationId(testAppId, DEFAULT_CLUSTER_NAME, namespaceName, notificationId)
+	}
+	return server
+}
+
 func testNewClient(t *testing.T) *Client {
-	client, err := NewClient("http://81.68.181.139:8080", "apollo-client-test")
+	client, err := NewClient(testNewServer(t).URL, testAppId)
 	if err != nil {
 		t.Fatal(err)
 	}
-	client.Secret = "4081edabfe4e4ba097cc16defc526c2f"
+	client.Secret = testSecret
 	return client
 }
 
-func testGetClient(t *testing.T) *Client {
-	syncOnce.Do(func() {
-		client = testNewClient(t)
-	})
-	return client
+// 获取一个没有监听的本地地址
+func testClosedAddress(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := listener.Addr().String()
+	_ = listener.Close()
+	return address
 }
@@ -0,0 +1,184 @@
+package client
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// 多个namespace组成的分层配置视图，读取时按优先级依次查找，越靠前的namespace优先级越高
+type Composite struct {
+	layers     []compositeLayer
+	refreshMu  sync.Mutex
+	mu         sync.RWMutex
+	effective  Configurations
+	listeners  []ChangeListener
+	unregister func()
+}
+
+type compositeLayer struct {
+	namespaceName string
+	source        Source
+}
+
+// 基于多个Configs构建静态的分层配置视图，越靠前的优先级越高
+func NewComposite(configs ...*Configs) *Composite {
+	composite := &Composite{}
+	for _, c := range configs {
+		composite.layers = append(composite.layers, compositeLayer{namespaceName: c.NamespaceName, source: c.Configurations})
+	}
+	composite.effective = composite.snapshot()
+	return composite
+}
+
+// 基于客户端配置仓库中的多个namespace构建分层配置视图，越靠前的namespace优先级越高，
+// 读取时总是使用最新的配置，namespace变更导致生效值变化时会通知 OnChange 注册的监听，
+// 不再使用时需要调用 Close 取消对客户端变更的监听
+func (c *Client) Composite(namespaceNames ...string) *Composite {
+	composite := c.newComposite(namespaceNames...)
+	composite.unregister = c.OnChange(func(event *ChangeEvent) {
+		if composite.contains(event.NamespaceName) {
+			composite.refresh(event)
+		}
+	})
+	return composite
+}
+
+// 构建不监听客户端变更的分层配置视图，读取时仍然使用最新的配置
+func (c *Client) newComposite(namespaceNames ...string) *Composite {
+	composite := &Composite{}
+	for _, namespaceName := range namespaceNames {
+		composite.layers = append(composite.layers, compositeLayer{
+			namespaceName: namespaceName,
+			source:        c.namespaceSource(namespaceName),
+		})
+	}
+	composite.effective = composite.snapshot()
+	return composite
+}
+
+// 取消对客户端变更的监听，之后 OnChange 注册的监听不会再被触发，读取不受影响
+func (cp *Composite) Close() {
+	if cp.unregister != nil {
+		cp.unregister()
+	}
+}
+
+// 获取所有namespace名称（按优先级排序）
+func (cp *Composite) NamespaceNames() []string {
+	namespaceNames := make([]string, 0, len(cp.layers))
+	for _, layer := range cp.layers {
+		namespaceNames = append(namespaceNames, layer.namespaceName)
+	}
+	return namespaceNames
+}
+
+// 按优先级查找配置值
+func (cp *Composite) Lookup(key string) (string, bool) {
+	value, _, ok := cp.LookupWithNamespace(key)
+	return value, ok
+}
+
+// 按优先级查找配置值，同时返回生效值所在的namespace
+func (cp *Composite) LookupWithNamespace(key string) (string, string, bool) {
+	value, namespaceName, err := cp.resolveWithNamespace(key)
+	return value, namespaceName, err == nil
+}
+
+// 按优先级查找配置值，key不存在时返回ErrKeyNotFound，读取失败（如解密失败）时返回对应的错误
+func (cp *Composite) Resolve(key string) (string, error) {
+	value, _, err := cp.resolveWithNamespace(key)
+	return value, err
+}
+
+func (cp *Composite) resolveWithNamespace(key string) (string, string, error) {
+	for _, layer := range cp.layers {
+		value, ok, err := lookup(layer.source, key)
+		if err != nil {
+			return "", "", err
+		}
+		if ok {
+			return value, layer.namespaceName, nil
+		}
+	}
+	return "", "", fmt.Errorf("%w: %s", ErrKeyNotFound, key)
+}
+
+// 获取所有namespace中的key（已排序）
+func (cp *Composite) Keys() []string {
+	seen := map[string]bool{}
+	keys := make([]string, 0)
+	for _, layer := range cp.layers {
+		keysSource, ok := layer.source.(KeysSource)
+		if !ok {
+			continue
+		}
+		for _, key := range keysSource.Keys() {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// 获取合并后的配置快照
+func (cp *Composite) Configurations() Configurations {
+	return cp.snapshot()
+}
+
+// 获取类型化读取实例
+func (cp *Composite) Config() *Config {
+	return NewConfig(cp)
+}
+
+// 注册变更监听，只有生效值发生变化时才会触发（被更高优先级namespace覆盖的key变更不会触发），
+// 事件的NamespaceName和ReleaseKey为引起变更的namespace
+func (cp *Composite) OnChange(listener ChangeListener) {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	cp.listeners = append(cp.listeners, listener)
+}
+
+// 重新计算生效值，与上一次的生效值对比后通知监听者
+func (cp *Composite) refresh(cause *ChangeEvent) {
+	cp.refreshMu.Lock()
+	defer cp.refreshMu.Unlock()
+	effective := cp.snapshot()
+
+	cp.mu.Lock()
+	event := diffConfigurations(cause.NamespaceName, cp.effective, effective)
+	event.ReleaseKey = cause.ReleaseKey
+	cp.effective = effective
+	listeners := cp.listeners
+	cp.mu.Unlock()
+
+	if event.IsEmpty() {
+		return
+	}
+	for _, listener := range listeners {
+		listener(event)
+	}
+}
+
+func (cp *Composite) snapshot() Configurations {
+	keys := cp.Keys()
+	effective := make(Configurations, len(keys))
+	for _, key := range keys {
+		if value, ok := cp.Lookup(key); ok {
+			effective[key] = value
+		}
+	}
+	return effective
+}
+
+func (cp *Composite) contains(namespaceName string) bool {
+	for _, layer := range cp.layers {
+		if layer.namespaceName == namespaceName {
+			return true
+		}
+	}
+	return false
+}
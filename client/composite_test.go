@@ -0,0 +1,75 @@
+package client
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestNewComposite(t *testing.T) {
+	composite := NewComposite(
+		&Configs{NamespaceName: "application", Configurations: Configurations{"timeout": "100"}},
+		&Configs{NamespaceName: "TEAM.test_case_1", Configurations: Configurations{"timeout": "200", "retry": "3"}},
+	)
+	if value, namespaceName, ok := composite.LookupWithNamespace("timeout"); !ok || value != "100" || namespaceName != "application" {
+		t.Fatal(fmt.Sprintf("unexpected lookup result: %s %s", value, namespaceName))
+	}
+	if retry, err := composite.Config().GetInt("retry"); err != nil || retry != 3 {
+		t.Fatal(fmt.Sprintf("lookup should fall through namespaces, retry: %d, err: %v", retry, err))
+	}
+	if !reflect.DeepEqual(composite.Configurations(), Configurations{"timeout": "100", "retry": "3"}) {
+		t.Fatal(fmt.Sprintf("unexpected configurations: %v", composite.Configurations()))
+	}
+	if !reflect.DeepEqual(composite.NamespaceNames(), []string{"application", "TEAM.test_case_1"}) {
+		t.Fatal(fmt.Sprintf("unexpected namespaceNames: %v", composite.NamespaceNames()))
+	}
+}
+
+func TestClientComposite(t *testing.T) {
+	client := &Client{}
+	client.applyConfigs(&Configs{NamespaceName: "application", Configurations: Configurations{"timeout": "100"}})
+	client.applyConfigs(&Configs{NamespaceName: "TEAM.test_case_1", Configurations: Configurations{"timeout": "200", "retry": "3"}})
+
+	composite := client.Composite("application", "TEAM.test_case_1")
+	events := make(chan *ChangeEvent, 10)
+	composite.OnChange(func(event *ChangeEvent) { events <- event })
+
+	//被application覆盖的key变更，生效值不变，不触发
+	client.applyConfigs(&Configs{NamespaceName: "TEAM.test_case_1", Configurations: Configurations{"timeout": "300", "retry": "3"}})
+	//不属于视图的namespace变更，不触发
+	client.applyConfigs(&Configs{NamespaceName: "other", Configurations: Configurations{"timeout": "1"}})
+	if len(events) != 0 {
+		t.Fatal("change of shadowed key should not emit event")
+	}
+
+	client.applyConfigs(&Configs{NamespaceName: "application", ReleaseKey: "release-1", Configurations: Configurations{}})
+	event := <-events
+	if event.NamespaceName != "application" || event.ReleaseKey != "release-1" {
+		t.Fatal(fmt.Sprintf("unexpected event source: %s %s", event.NamespaceName, event.ReleaseKey))
+	}
+	if len(event.Modified) != 1 || event.Modified["timeout"] != (ValueChange{OldValue: "100", NewValue: "300"}) {
+		t.Fatal(fmt.Sprintf("effective value should fall through to lower namespace: %v", event.Modified))
+	}
+
+	client.applyConfigs(&Configs{NamespaceName: "TEAM.test_case_1", Configurations: Configurations{"timeout": "300"}})
+	event = <-events
+	if len(event.Deleted) != 1 || event.Deleted["retry"] != "3" || len(event.Modified) != 0 {
+		t.Fatal(fmt.Sprintf("unexpected change event: %v", event))
+	}
+	if timeout, err := composite.Config().GetInt("timeout"); err != nil || timeout != 300 {
+		t.Fatal(fmt.Sprintf("unexpected timeout: %d, err: %v", timeout, err))
+	}
+
+	//关闭后不再监听客户端变更，读取仍然使用最新的配置
+	composite.Close()
+	if len(client.changes.listeners) != 0 {
+		t.Fatal(fmt.Sprintf("Close should unregister the listener, got: %d", len(client.changes.listeners)))
+	}
+	client.applyConfigs(&Configs{NamespaceName: "TEAM.test_case_1", Configurations: Configurations{"timeout": "400"}})
+	if len(events) != 0 {
+		t.Fatal("closed composite should not emit event")
+	}
+	if value, _ := composite.Lookup("timeout"); value != "400" {
+		t.Fatal(fmt.Sprintf("closed composite should still read latest configs: %s", value))
+	}
+}
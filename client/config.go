@@ -0,0 +1,219 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrKeyNotFound = errors.New("key not found")
+
+// 配置值来源
+type Source interface {
+	Lookup(key string) (string, bool)
+}
+
+type Config struct {
+	source Source
+}
+
+type ParseError struct {
+	Key   string
+	Value string
+	Type  string
+	Err   error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("Unable to parse key %s value %q as %s: %v", e.Key, e.Value, e.Type, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// 查找配置值
+func (c Configurations) Lookup(key string) (string, bool) {
+	value, ok := c[key]
+	return value, ok
+}
+
+// 获取所有key（已排序）
+func (c Configurations) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// 基于配置值来源构建一个类型化读取实例
+func NewConfig(source Source) *Config {
+	return &Config{source: source}
+}
+
+// 读取字符串，key不存在时返回ErrKeyNotFound
+func (c *Config) GetString(key string) (string, error) {
+	value, ok, err := lookup(c.source, key)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrKeyNotFound, key)
+	}
+	return value, nil
+}
+
+func (c *Config) GetStringOrDefault(key, defaultValue string) string {
+	value, err := c.GetString(key)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+func (c *Config) GetInt(key string) (int, error) {
+	value, err := c.parse(key, "int", func(s string) (interface{}, error) { return strconv.Atoi(s) })
+	if err != nil {
+		return 0, err
+	}
+	return value.(int), nil
+}
+
+func (c *Config) GetIntOrDefault(key string, defaultValue int) int {
+	value, err := c.GetInt(key)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+func (c *Config) GetInt64(key string) (int64, error) {
+	value, err := c.parse(key, "int64", func(s string) (interface{}, error) { return strconv.ParseInt(s, 10, 64) })
+	if err != nil {
+		return 0, err
+	}
+	return value.(int64), nil
+}
+
+func (c *Config) GetInt64OrDefault(key string, defaultValue int64) int64 {
+	value, err := c.GetInt64(key)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+func (c *Config) GetBool(key string) (bool, error) {
+	value, err := c.parse(key, "bool", func(s string) (interface{}, error) { return strconv.ParseBool(s) })
+	if err != nil {
+		return false, err
+	}
+	return value.(bool), nil
+}
+
+func (c *Config) GetBoolOrDefault(key string, defaultValue bool) bool {
+	value, err := c.GetBool(key)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+func (c *Config) GetFloat64(key string) (float64, error) {
+	value, err := c.parse(key, "float64", func(s string) (interface{}, error) { return strconv.ParseFloat(s, 64) })
+	if err != nil {
+		return 0, err
+	}
+	return value.(float64), nil
+}
+
+func (c *Config) GetFloat64OrDefault(key string, defaultValue float64) float64 {
+	value, err := c.GetFloat64(key)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// 读取时长，支持 time.ParseDuration 的格式（如 1m30s），纯数字按毫秒处理
+func (c *Config) GetDuration(key string) (time.Duration, error) {
+	value, err := c.parse(key, "duration", parseDuration)
+	if err != nil {
+		return 0, err
+	}
+	return value.(time.Duration), nil
+}
+
+func (c *Config) GetDurationOrDefault(key string, defaultValue time.Duration) time.Duration {
+	value, err := c.GetDuration(key)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// 按分隔符读取字符串切片，每一项会去除首尾空白，空字符串返回空切片
+func (c *Config) GetStringSlice(key, sep string) ([]string, error) {
+	value, err := c.GetString(key)
+	if err != nil {
+		return nil, err
+	}
+	return splitString(value, sep), nil
+}
+
+func (c *Config) GetStringSliceOrDefault(key, sep string, defaultValue []string) []string {
+	value, err := c.GetStringSlice(key, sep)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// 读取并解析配置值，解析失败返回ParseError
+func (c *Config) parse(key, typ string, parser func(s string) (interface{}, error)) (interface{}, error) {
+	value, err := c.GetString(key)
+	if err != nil {
+		return nil, err
+	}
+	res, err := parser(strings.TrimSpace(value))
+	if err != nil {
+		return nil, &ParseError{Key: key, Value: value, Type: typ, Err: err}
+	}
+	return res, nil
+}
+
+// 从来源查找配置值，来源支持解析（如Resolver）时返回解析错误
+func lookup(source Source, key string) (string, bool, error) {
+	if resolver, ok := source.(resolvingSource); ok {
+		value, err := resolver.Resolve(key)
+		if errors.Is(err, ErrKeyNotFound) {
+			return "", false, nil
+		}
+		return value, err == nil, err
+	}
+	value, ok := source.Lookup(key)
+	return value, ok, nil
+}
+
+func parseDuration(s string) (interface{}, error) {
+	if ms, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Duration(ms) * time.Millisecond, nil
+	}
+	return time.ParseDuration(s)
+}
+
+func splitString(s, sep string) []string {
+	if strings.TrimSpace(s) == "" {
+		return []string{}
+	}
+	items := strings.Split(s, sep)
+	for i, item := range items {
+		items[i] = strings.TrimSpace(item)
+	}
+	return items
+}
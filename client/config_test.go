@@ -0,0 +1,78 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestConfigGetters(t *testing.T) {
+	config := NewConfig(Configurations{
+		"name":     "apollo",
+		"port":     " 8080 ",
+		"big":      "9223372036854775807",
+		"enable":   "true",
+		"ratio":    "0.75",
+		"timeout":  "1m30s",
+		"interval": "500",
+		"hosts":    "a.com, b.com,c.com",
+		"empty":    "",
+		"invalid":  "abc",
+	})
+
+	if v, err := config.GetString("name"); err != nil || v != "apollo" {
+		t.Fatal(fmt.Sprintf("GetString error, value: %s, err: %v", v, err))
+	}
+	if v, err := config.GetInt("port"); err != nil || v != 8080 {
+		t.Fatal(fmt.Sprintf("GetInt error, value: %d, err: %v", v, err))
+	}
+	if v, err := config.GetInt64("big"); err != nil || v != 9223372036854775807 {
+		t.Fatal(fmt.Sprintf("GetInt64 error, value: %d, err: %v", v, err))
+	}
+	if v, err := config.GetBool("enable"); err != nil || !v {
+		t.Fatal(fmt.Sprintf("GetBool error, value: %v, err: %v", v, err))
+	}
+	if v, err := config.GetFloat64("ratio"); err != nil || v != 0.75 {
+		t.Fatal(fmt.Sprintf("GetFloat64 error, value: %v, err: %v", v, err))
+	}
+	if v, err := config.GetDuration("timeout"); err != nil || v != 90*time.Second {
+		t.Fatal(fmt.Sprintf("GetDuration error, value: %v, err: %v", v, err))
+	}
+	if v, err := config.GetDuration("interval"); err != nil || v != 500*time.Millisecond {
+		t.Fatal(fmt.Sprintf("GetDuration error, value: %v, err: %v", v, err))
+	}
+	if v, err := config.GetStringSlice("hosts", ","); err != nil || !reflect.DeepEqual(v, []string{"a.com", "b.com", "c.com"}) {
+		t.Fatal(fmt.Sprintf("GetStringSlice error, value: %v, err: %v", v, err))
+	}
+	if v, err := config.GetStringSlice("empty", ","); err != nil || len(v) != 0 {
+		t.Fatal(fmt.Sprintf("GetStringSlice error, value: %v, err: %v", v, err))
+	}
+
+	//key不存在
+	if _, err := config.GetInt("missing"); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatal(fmt.Sprintf("GetInt should return ErrKeyNotFound, err: %v", err))
+	}
+
+	//解析失败
+	_, err := config.GetInt("invalid")
+	var parseError *ParseError
+	if !errors.As(err, &parseError) || parseError.Key != "invalid" || parseError.Type != "int" {
+		t.Fatal(fmt.Sprintf("GetInt should return ParseError, err: %v", err))
+	}
+
+	//默认值
+	if config.GetStringOrDefault("missing", "x") != "x" ||
+		config.GetIntOrDefault("invalid", 1) != 1 ||
+		config.GetInt64OrDefault("missing", 2) != 2 ||
+		config.GetBoolOrDefault("invalid", true) != true ||
+		config.GetFloat64OrDefault("missing", 0.5) != 0.5 ||
+		config.GetDurationOrDefault("invalid", time.Second) != time.Second ||
+		!reflect.DeepEqual(config.GetStringSliceOrDefault("missing", ",", []string{"d"}), []string{"d"}) {
+		t.Fatal("OrDefault getters should return default value")
+	}
+	if config.GetIntOrDefault("port", 1) != 8080 {
+		t.Fatal("GetIntOrDefault should return value when key exists")
+	}
+}
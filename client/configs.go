@@ -1,12 +1,14 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/flylan/apollo-config-lib/request"
 	"github.com/flylan/apollo-config-lib/utils"
 	"net"
+	"net/http"
 	"net/url"
 	"sync"
 )
@@ -28,45 +30,95 @@ type Configs struct {
 	NamespaceName  string         `json:"namespaceName"`
 	Configurations Configurations `json:"configurations"`
 	ReleaseKey     string         `json:"releaseKey"`
+	FromCache      bool           `json:"-"`
 }
 
-// 构建一个获取配置实例
-func (c *Client) Configs(namespaceName string) *ConfigsParam {
-	//应用部署的机器ip
-	var ip net.IP
+// 获取应用部署的机器ip，配置了Ip时优先使用
+func (c *Client) outboundIP() net.IP {
+	if !utils.IsByteSliceEmpty(c.Ip) {
+		return c.Ip
+	}
 	if cache, ok := ipCache.Load(c.address); ok {
-		ip = cache.(net.IP)
-	} else {
-		ip, _ = utils.GetOutboundIP(c.address)
-		ipCache.Store(c.address, ip)
+		return cache.(net.IP)
 	}
+	ip, _ := utils.GetOutboundIP(c.address)
+	ipCache.Store(c.address, ip)
+	return ip
+}
+
+// 构建一个获取配置实例
+func (c *Client) Configs(namespaceName string) *ConfigsParam {
 	return &ConfigsParam{
 		Client:        c,
-		Ip:            ip,
+		Ip:            c.outboundIP(),
 		UseNoCacheApi: true,
 		NamespaceName: namespaceName,
+		Label:         c.Label,
 	}
 }
 
-// 从Apollo读取配置
+// 从Apollo读取配置，配置了本地缓存时，网络错误或服务端返回5xx会使用本地缓存兜底（返回的Configs.FromCache为true）
 func (cp *ConfigsParam) Get() (*Configs, *request.Info, error) {
+	return cp.GetWithContext(context.Background())
+}
+
+// 从Apollo读取配置，支持通过ctx取消请求或设置截止时间
+func (cp *ConfigsParam) GetWithContext(ctx context.Context) (*Configs, *request.Info, error) {
+	configs, info, err := cp.getFromServer(ctx)
+	if err == nil || cp.NamespaceName == "" || cp.Client.Cache == nil || !canFallbackToCache(ctx, err) {
+		return configs, info, err
+	}
+	cached, cacheErr := cp.Client.loadCache(cp.NamespaceName)
+	if cacheErr != nil {
+		return nil, info, err
+	}
+	return cached, info, nil
+}
+
+// 判断读取失败时能否使用本地缓存兜底，ctx被取消、4xx（如namespace不存在、签名错误）以及响应无法解析时直接返回错误
+func canFallbackToCache(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode >= http.StatusInternalServerError
+	}
+	var decodeErr *DecodeError
+	return !errors.As(err, &decodeErr)
+}
+
+// 从Apollo服务端读取配置，读取成功后写入本地缓存
+func (cp *ConfigsParam) getFromServer(ctx context.Context) (*Configs, *request.Info, error) {
 	//初始化info
 	info := &request.Info{}
 	//必须传入NamespaceName
 	if cp.NamespaceName == "" {
 		return nil, info, errors.New("NamespaceName is empty")
 	}
+
+	var configs *Configs
+	var err error
 	if cp.UseNoCacheApi {
-		return cp.noCacheGet(info)
+		configs, info, err = cp.noCacheGet(ctx, info)
+	} else {
+		configs, info, err = cp.get(ctx, info)
+	}
+	if err != nil || info.IsDataNotModified() {
+		return configs, info, err
+	}
+
+	//本地缓存写入失败不影响本次读取
+	if err = cp.Client.saveCache(cp.NamespaceName, configs); err != nil {
+		cp.Client.logf("Unable to save namespace %s to local cache: %v", cp.NamespaceName, err)
 	}
-	return cp.get(info)
+	return configs, info, nil
 }
 
-// 构造基础请求链接
-func (cp *ConfigsParam) buildBaseURL(format string) string {
+// 构造基础请求路径（不包含config service地址）
+func (cp *ConfigsParam) buildBasePath(format string) string {
 	return fmt.Sprintf(
 		format,
-		cp.Client.ConfigServerUrl,
 		cp.Client.AppId,
 		cp.Client.ClusterName,
 		cp.NamespaceName,
@@ -74,33 +126,33 @@ func (cp *ConfigsParam) buildBaseURL(format string) string {
 }
 
 // 发起获取配置请求
-func (cp *ConfigsParam) sendGetRequest(requestUrl string, info *request.Info) (*request.Info, error) {
-	return request.SendGetRequest(
-		requestUrl,
-		cp.Client.AppId,
-		cp.Client.Secret,
+func (cp *ConfigsParam) sendGetRequest(ctx context.Context, pathWithQuery string, info *request.Info) (*request.Info, error) {
+	return cp.Client.sendGetRequest(
+		ctx,
+		func(configServerUrl string) string { return configServerUrl + pathWithQuery },
 		cp.Client.RequestTimeout.GetConfigs,
+		false,
 		info,
 	)
 }
 
 // 通过带缓存的Http接口从Apollo读取配置
-func (cp *ConfigsParam) get(info *request.Info) (*Configs, *request.Info, error) {
-	//构建请求链接
-	requestUrl := cp.buildBaseURL("%s/configfiles/json/%s/%s/%s")
+func (cp *ConfigsParam) get(ctx context.Context, info *request.Info) (*Configs, *request.Info, error) {
+	//构建请求路径
+	requestPath := cp.buildBasePath("/configfiles/json/%s/%s/%s")
 	if !utils.IsByteSliceEmpty(cp.Ip) {
-		requestUrl = fmt.Sprintf("%s?ip=%s", requestUrl, cp.Ip)
+		requestPath = fmt.Sprintf("%s?ip=%s", requestPath, cp.Ip)
 	}
 
 	//发送get请求
-	info, err := cp.sendGetRequest(requestUrl, info)
+	info, err := cp.sendGetRequest(ctx, requestPath, info)
 	if err != nil {
 		return nil, info, err
 	}
 
 	//带缓存接口只需要判断200状态码
 	if !info.IsGetDataSuccess() {
-		return nil, info, errors.New(fmt.Sprintf("%s returns HTTP status code: %d", requestUrl, info.StatusCode))
+		return nil, info, request.NewHTTPError(info)
 	}
 
 	//转换json字符串为结构体
@@ -108,7 +160,7 @@ func (cp *ConfigsParam) get(info *request.Info) (*Configs, *request.Info, error)
 	if !utils.IsByteSliceEmpty(info.ResponseBody) {
 		err = json.Unmarshal(info.ResponseBody, configurations)
 		if err != nil {
-			return nil, info, err
+			return nil, info, request.NewDecodeError(info, err)
 		}
 	}
 
@@ -121,8 +173,8 @@ func (cp *ConfigsParam) get(info *request.Info) (*Configs, *request.Info, error)
 }
 
 // 通过不带缓存的Http接口从Apollo读取配置
-func (cp *ConfigsParam) noCacheGet(info *request.Info) (*Configs, *request.Info, error) {
-	requestUrl := cp.buildBaseURL("%s/configs/%s/%s/%s")
+func (cp *ConfigsParam) noCacheGet(ctx context.Context, info *request.Info) (*Configs, *request.Info, error) {
+	requestPath := cp.buildBasePath("/configs/%s/%s/%s")
 	params := url.Values{}
 
 	//上一次的releaseKey
@@ -145,21 +197,21 @@ func (cp *ConfigsParam) noCacheGet(info *request.Info) (*Configs, *request.Info,
 		params.Add("ip", cp.Ip.String())
 	}
 
-	//构建最终请求链接
+	//构建最终请求路径
 	queryStr := params.Encode()
 	if queryStr != "" {
-		requestUrl = fmt.Sprintf("%s?%s", requestUrl, queryStr)
+		requestPath = fmt.Sprintf("%s?%s", requestPath, queryStr)
 	}
 
 	//发送get请求
-	info, err := cp.sendGetRequest(requestUrl, info)
+	info, err := cp.sendGetRequest(ctx, requestPath, info)
 	if err != nil {
 		return nil, info, err
 	}
 
 	//不带缓存接口，可能返回200或者304状态码
 	if !info.IsGetDataSuccess() && !info.IsDataNotModified() {
-		return nil, info, fmt.Errorf("%s returns HTTP status code: %d", requestUrl, info.StatusCode)
+		return nil, info, request.NewHTTPError(info)
 	}
 
 	//转换json字符串为结构体
@@ -167,7 +219,7 @@ func (cp *ConfigsParam) noCacheGet(info *request.Info) (*Configs, *request.Info,
 	if !utils.IsByteSliceEmpty(info.ResponseBody) {
 		err = json.Unmarshal(info.ResponseBody, configs)
 		if err != nil {
-			return nil, info, err
+			return nil, info, request.NewDecodeError(info, err)
 		}
 	}
 
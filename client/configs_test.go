@@ -59,7 +59,7 @@ func checkConfigs(t *testing.T, namespaceName string, noCache bool) {
 }
 
 func testConfigsGet(t *testing.T, namespaceName string, noCache bool) (*Client, *Configs, *request.Info) {
-	client := testGetClient(t)
+	client := testNewClient(t)
 	cp := client.Configs(namespaceName)
 	if !noCache {
 		cp.UseNoCacheApi = false
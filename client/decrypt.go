@@ -0,0 +1,156 @@
+package client
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	ENCRYPTED_PREFIX = "ENC("
+	ENCRYPTED_SUFFIX = ")"
+)
+
+var ErrDecrypt = errors.New("unable to decrypt value")
+
+// 解密器，ciphertext为 ENC(...) 括号内的内容，可以对接KMS、Vault等外部服务
+type Decryptor interface {
+	Decrypt(ciphertext string) (string, error)
+}
+
+type DecryptorFunc func(ciphertext string) (string, error)
+
+// 内置的AES-GCM解密器，密文格式为 base64(nonce + 密文 + tag)
+type AESGCMDecryptor struct {
+	aead cipher.AEAD
+}
+
+// 解密后的配置值来源，读取时对 ENC(...) 格式的值解密，原始配置不会被修改
+type decryptingSource struct {
+	source    Source
+	decryptor Decryptor
+}
+
+func (f DecryptorFunc) Decrypt(ciphertext string) (string, error) {
+	return f(ciphertext)
+}
+
+// 基于AES密钥构建解密器，密钥长度必须为16、24或32字节
+func NewAESGCMDecryptor(key []byte) (*AESGCMDecryptor, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &AESGCMDecryptor{aead: aead}, nil
+}
+
+func (d *AESGCMDecryptor) Decrypt(ciphertext string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return "", err
+	}
+	nonceSize := d.aead.NonceSize()
+	if len(data) < nonceSize {
+		return "", errors.New("ciphertext too short")
+	}
+	plaintext, err := d.aead.Open(nil, data[:nonceSize], data[nonceSize:], nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// 加密明文，返回可以直接保存到Apollo的 ENC(...) 格式的值
+func (d *AESGCMDecryptor) Encrypt(plaintext string) (string, error) {
+	nonce := make([]byte, d.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	data := d.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return ENCRYPTED_PREFIX + base64.StdEncoding.EncodeToString(data) + ENCRYPTED_SUFFIX, nil
+}
+
+// 判断配置值是否为 ENC(...) 格式的加密值
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, ENCRYPTED_PREFIX) && strings.HasSuffix(value, ENCRYPTED_SUFFIX) &&
+		len(value) >= len(ENCRYPTED_PREFIX)+len(ENCRYPTED_SUFFIX)
+}
+
+// 解密配置值，非加密值原样返回，解密失败返回ErrDecrypt
+func Decrypt(decryptor Decryptor, key, value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	plaintext, err := decryptor.Decrypt(value[len(ENCRYPTED_PREFIX) : len(value)-len(ENCRYPTED_SUFFIX)])
+	if err != nil {
+		return "", fmt.Errorf("%w %s: %v", ErrDecrypt, key, err)
+	}
+	return plaintext, nil
+}
+
+// 解密所有加密值，返回新的配置，原始配置不会被修改
+func (c Configurations) Decrypt(decryptor Decryptor) (Configurations, error) {
+	decrypted := make(Configurations, len(c))
+	for key, value := range c {
+		plaintext, err := Decrypt(decryptor, key, value)
+		if err != nil {
+			return nil, err
+		}
+		decrypted[key] = plaintext
+	}
+	return decrypted, nil
+}
+
+// 基于解密器包装配置值来源，读取时透明解密，decryptor为nil时直接返回source
+func NewDecryptingSource(source Source, decryptor Decryptor) Source {
+	if decryptor == nil {
+		return source
+	}
+	return &decryptingSource{source: source, decryptor: decryptor}
+}
+
+func (s *decryptingSource) Resolve(key string) (string, error) {
+	value, ok, err := lookup(s.source, key)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrKeyNotFound, key)
+	}
+	return Decrypt(s.decryptor, key, value)
+}
+
+func (s *decryptingSource) Lookup(key string) (string, bool) {
+	value, err := s.Resolve(key)
+	return value, err == nil
+}
+
+func (s *decryptingSource) Keys() []string {
+	if keysSource, ok := s.source.(KeysSource); ok {
+		return keysSource.Keys()
+	}
+	return nil
+}
+
+func (s *decryptingSource) LookupWithNamespace(key string) (string, string, bool) {
+	base, ok := s.source.(namespaceLookup)
+	if !ok {
+		value, ok := s.Lookup(key)
+		return value, "", ok
+	}
+	value, namespaceName, ok := base.LookupWithNamespace(key)
+	if !ok {
+		return "", "", false
+	}
+	value, err := Decrypt(s.decryptor, key, value)
+	return value, namespaceName, err == nil
+}
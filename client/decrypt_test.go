@@ -0,0 +1,102 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"github.com/flylan/apollo-config-lib/apollotest"
+	"strings"
+	"testing"
+)
+
+func TestAESGCMDecryptor(t *testing.T) {
+	if _, err := NewAESGCMDecryptor([]byte("short")); err == nil {
+		t.Fatal("NewAESGCMDecryptor should return error when key size is invalid")
+	}
+	decryptor, err := NewAESGCMDecryptor([]byte("0123456789abcdef0123456789abcdef"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err := decryptor.Encrypt("p@ssw0rd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsEncrypted(encrypted) {
+		t.Fatal(fmt.Sprintf("Encrypt should return ENC(...) value: %s", encrypted))
+	}
+	if plaintext, err := Decrypt(decryptor, "password", encrypted); err != nil || plaintext != "p@ssw0rd" {
+		t.Fatal(fmt.Sprintf("unexpected plaintext: %s, err: %v", plaintext, err))
+	}
+	if plaintext, err := Decrypt(decryptor, "name", "ENC"); err != nil || plaintext != "ENC" {
+		t.Fatal(fmt.Sprintf("plain value should be returned as is: %s, err: %v", plaintext, err))
+	}
+
+	other, _ := NewAESGCMDecryptor([]byte("fedcba9876543210"))
+	if _, err = Decrypt(other, "password", encrypted); !errors.Is(err, ErrDecrypt) {
+		t.Fatal(fmt.Sprintf("Decrypt with wrong key should return ErrDecrypt, err: %v", err))
+	}
+	if _, err = Decrypt(decryptor, "password", "ENC(not-base64)"); !errors.Is(err, ErrDecrypt) {
+		t.Fatal(fmt.Sprintf("Decrypt invalid ciphertext should return ErrDecrypt, err: %v", err))
+	}
+}
+
+func TestClientDecryptor(t *testing.T) {
+	//模拟外部的KMS服务
+	kms := DecryptorFunc(func(ciphertext string) (string, error) {
+		if !strings.HasPrefix(ciphertext, "kms:") {
+			return "", errors.New("unknown key")
+		}
+		return strings.TrimPrefix(ciphertext, "kms:"), nil
+	})
+
+	server := apollotest.NewServer()
+	defer server.Close()
+	server.Publish(testAppId, DEFAULT_CLUSTER_NAME, "application", Configurations{
+		"db.password": "ENC(kms:secret)",
+		"db.user":     "root",
+		"broken":      "ENC(vault:secret)",
+	})
+
+	cache := NewFileCache(t.TempDir())
+	client, err := NewClient(server.URL, testAppId, WithDecryptor(kms), WithCache(cache))
+	if err != nil {
+		t.Fatal(err)
+	}
+	configs, _, err := client.Configs("application").Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.applyConfigs(configs)
+
+	config := client.Config("application")
+	if password, err := config.GetString("db.password"); err != nil || password != "secret" {
+		t.Fatal(fmt.Sprintf("unexpected password: %s, err: %v", password, err))
+	}
+	if user, err := config.GetString("db.user"); err != nil || user != "root" {
+		t.Fatal(fmt.Sprintf("unexpected user: %s, err: %v", user, err))
+	}
+	if _, err = config.GetString("broken"); !errors.Is(err, ErrDecrypt) {
+		t.Fatal(fmt.Sprintf("GetString should return ErrDecrypt, err: %v", err))
+	}
+	if _, err = client.Composite("application").Config().GetString("broken"); !errors.Is(err, ErrDecrypt) {
+		t.Fatal(fmt.Sprintf("Composite should return ErrDecrypt, err: %v", err))
+	}
+	if value, err := NewConfig(client.Resolver("application")).GetString("db.password"); err != nil || value != "secret" {
+		t.Fatal(fmt.Sprintf("Resolver should read decrypted value: %s, err: %v", value, err))
+	}
+
+	if configs.Configurations["db.password"] != "ENC(kms:secret)" {
+		t.Fatal("Configs should keep encrypted value")
+	}
+	cached, err := cache.Load(testAppId, DEFAULT_CLUSTER_NAME, "application")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cached.Configurations["db.password"] != "ENC(kms:secret)" {
+		t.Fatal(fmt.Sprintf("cache should keep encrypted value: %s", cached.Configurations["db.password"]))
+	}
+
+	decrypted, err := Configurations{"a": "ENC(kms:1)", "b": "2"}.Decrypt(kms)
+	if err != nil || decrypted["a"] != "1" || decrypted["b"] != "2" {
+		t.Fatal(fmt.Sprintf("unexpected decrypted configurations: %v, err: %v", decrypted, err))
+	}
+}
@@ -0,0 +1,202 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/flylan/apollo-config-lib/request"
+	"github.com/flylan/apollo-config-lib/utils"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DEFAULT_DISCOVERY_REFRESH_INTERVAL = 5 * time.Minute
+	DEFAULT_DISCOVERY_TIMEOUT          = 5 * time.Second
+)
+
+type ServiceInstance struct {
+	AppName     string `json:"appName"`
+	InstanceId  string `json:"instanceId"`
+	HomepageUrl string `json:"homepageUrl"`
+}
+
+// 通过meta server发现可用的config service实例
+type ServiceDiscovery struct {
+	//meta server地址，多个地址用逗号分隔，刷新时依次尝试直到成功
+	MetaServerUrl   string
+	RefreshInterval time.Duration
+	Timeout         time.Duration
+	HttpClient      *http.Client
+	//请求meta server时使用的签名器，为空时不签名
+	Signer request.Signer
+
+	mu          sync.RWMutex
+	instances   []ServiceInstance
+	refreshedAt time.Time
+	//最近一次刷新的时间和结果，刷新失败也会记录，避免每次请求都阻塞在meta server上
+	attemptedAt time.Time
+	lastErr     error
+	refreshing  bool
+	//没有实例时同步刷新，保证并发请求只会触发一次刷新
+	refreshMu sync.Mutex
+}
+
+// 构建一个服务发现实例
+func NewServiceDiscovery(metaServerUrl string) *ServiceDiscovery {
+	return &ServiceDiscovery{
+		MetaServerUrl:   strings.TrimRight(metaServerUrl, "/"),
+		RefreshInterval: DEFAULT_DISCOVERY_REFRESH_INTERVAL,
+		Timeout:         DEFAULT_DISCOVERY_TIMEOUT,
+	}
+}
+
+// 通过meta server构建客户端，config service实例列表会定期刷新，多个meta server地址用逗号分隔
+// 开启WithReachabilityCheck时会立即拉取一次实例列表，否则在第一次请求时拉取
+func NewClientWithMetaServer(metaServerUrl, appId string, opts ...Option) (*Client, error) {
+	if metaServerUrl == "" {
+		return nil, errors.New("MetaServerUrl is empty")
+	}
+	c, err := NewClient(metaServerUrl, appId, opts...)
+	if err != nil {
+		return nil, err
+	}
+	c.ConfigServerUrl = ""
+	c.Discovery = NewServiceDiscovery(metaServerUrl)
+	c.Discovery.HttpClient = c.HttpClient
+	c.Discovery.Signer = c.Signer
+	if c.probeTimeout > 0 {
+		if err = c.Discovery.Refresh(c.AppId, c.outboundIP()); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// 从meta server刷新config service实例列表
+func (d *ServiceDiscovery) Refresh(appId string, ip net.IP) error {
+	return d.RefreshWithContext(context.Background(), appId, ip)
+}
+
+// 从meta server刷新config service实例列表，支持通过ctx取消
+func (d *ServiceDiscovery) RefreshWithContext(ctx context.Context, appId string, ip net.IP) error {
+	params := url.Values{}
+	if appId != "" {
+		params.Add("appId", appId)
+	}
+	if !utils.IsByteSliceEmpty(ip) {
+		params.Add("ip", ip.String())
+	}
+
+	var instances []ServiceInstance
+	err := errors.New("MetaServerUrl is empty")
+	for _, metaServerUrl := range splitConfigServerUrl(d.MetaServerUrl) {
+		if instances, err = d.fetch(ctx, metaServerUrl, params, appId); err == nil || ctx.Err() != nil {
+			break
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.attemptedAt = time.Now()
+	d.lastErr = err
+	if err != nil {
+		return err
+	}
+	d.instances = instances
+	d.refreshedAt = d.attemptedAt
+	return nil
+}
+
+// 请求meta server获取config service实例列表
+func (d *ServiceDiscovery) fetch(ctx context.Context, metaServerUrl string, params url.Values, appId string) ([]ServiceInstance, error) {
+	requestUrl := fmt.Sprintf("%s/services/config", metaServerUrl)
+	if queryStr := params.Encode(); queryStr != "" {
+		requestUrl = fmt.Sprintf("%s?%s", requestUrl, queryStr)
+	}
+	sender := &request.Sender{HttpClient: d.HttpClient, Signer: d.Signer}
+	info, err := sender.SendGetRequest(ctx, requestUrl, appId, "", d.Timeout, &request.Info{})
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsGetDataSuccess() {
+		return nil, request.NewHTTPError(info)
+	}
+
+	var instances []ServiceInstance
+	if err = json.Unmarshal(info.ResponseBody, &instances); err != nil {
+		return nil, request.NewDecodeError(info, err)
+	}
+	if len(instances) == 0 {
+		return nil, fmt.Errorf("No config service instance found from meta server: %s", metaServerUrl)
+	}
+	return instances, nil
+}
+
+// 同步刷新实例列表，等待期间其他请求已经完成刷新时直接使用其结果
+func (d *ServiceDiscovery) refreshOnce(ctx context.Context, appId string, ip net.IP) error {
+	start := time.Now()
+	d.refreshMu.Lock()
+	defer d.refreshMu.Unlock()
+	d.mu.RLock()
+	attempted, lastErr := d.attemptedAt.After(start), d.lastErr
+	d.mu.RUnlock()
+	if attempted {
+		return lastErr
+	}
+	return d.RefreshWithContext(ctx, appId, ip)
+}
+
+// 获取当前缓存的config service实例列表
+func (d *ServiceDiscovery) Instances() []ServiceInstance {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	instances := make([]ServiceInstance, len(d.instances))
+	copy(instances, d.instances)
+	return instances
+}
+
+// 获取config service地址列表，没有实例时同步刷新；超过刷新间隔时在后台刷新并继续使用旧的列表
+func (d *ServiceDiscovery) ConfigServerUrls(appId string, ip net.IP) ([]string, error) {
+	return d.ConfigServerUrlsWithContext(context.Background(), appId, ip)
+}
+
+// 获取config service地址列表，支持通过ctx取消刷新
+func (d *ServiceDiscovery) ConfigServerUrlsWithContext(ctx context.Context, appId string, ip net.IP) ([]string, error) {
+	d.mu.Lock()
+	empty := len(d.instances) == 0
+	stale := d.RefreshInterval > 0 && time.Since(d.attemptedAt) >= d.RefreshInterval
+	background := !empty && stale && !d.refreshing
+	if background {
+		d.refreshing = true
+	}
+	d.mu.Unlock()
+
+	if empty {
+		if err := d.refreshOnce(ctx, appId, ip); err != nil {
+			return nil, err
+		}
+	} else if background {
+		go func() {
+			_ = d.RefreshWithContext(context.Background(), appId, ip)
+			d.mu.Lock()
+			d.refreshing = false
+			d.mu.Unlock()
+		}()
+	}
+
+	instances := d.Instances()
+	if len(instances) == 0 {
+		return nil, fmt.Errorf("No config service instance found from meta server: %s", d.MetaServerUrl)
+	}
+	urls := make([]string, 0, len(instances))
+	for _, instance := range instances {
+		urls = append(urls, strings.TrimRight(instance.HomepageUrl, "/"))
+	}
+	return urls, nil
+}
@@ -0,0 +1,233 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/flylan/apollo-config-lib/apollotest"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// 测试用的meta server，返回可修改的config service实例列表
+type metaTestServer struct {
+	mu        sync.Mutex
+	instances []ServiceInstance
+}
+
+func (s *metaTestServer) setInstances(homepageUrls ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.instances = nil
+	for i, homepageUrl := range homepageUrls {
+		s.instances = append(s.instances, ServiceInstance{
+			AppName:     "APOLLO-CONFIGSERVICE",
+			InstanceId:  fmt.Sprintf("config-service-%d", i),
+			HomepageUrl: homepageUrl + "/",
+		})
+	}
+}
+
+func (s *metaTestServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r.URL.Path != "/services/config" || r.URL.Query().Get("appId") == "" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(s.instances)
+}
+
+func TestNewClientWithMetaServer(t *testing.T) {
+	configServer := apollotest.NewServer()
+	defer configServer.Close()
+	configServer.Publish(testAppId, DEFAULT_CLUSTER_NAME, "application", Configurations{"timeout": "100"})
+
+	meta := &metaTestServer{}
+	metaServer := httptest.NewServer(meta)
+	defer metaServer.Close()
+
+	if _, err := NewClientWithMetaServer(metaServer.URL, "apollo-client-test", WithReachabilityCheck(time.Second)); err == nil {
+		t.Fatal("NewClientWithMetaServer should return error when no instance found")
+	}
+
+	meta.setInstances(configServer.URL)
+	client, err := NewClientWithMetaServer(metaServer.URL, "apollo-client-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(client.Discovery.Instances()) != 0 {
+		t.Fatal("instances should be loaded lazily without reachability check")
+	}
+
+	configs, _, err := client.Configs("application").Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if configs.Configurations["timeout"] != "100" {
+		t.Fatal(fmt.Sprintf("unexpected configs: %v", configs))
+	}
+	instances := client.Discovery.Instances()
+	if len(instances) != 1 || instances[0].InstanceId != "config-service-0" {
+		t.Fatal(fmt.Sprintf("unexpected instances: %v", instances))
+	}
+	notifications, _, err := client.Notifications("application").Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*notifications) != 1 {
+		t.Fatal(fmt.Sprintf("unexpected notifications: %v", notifications))
+	}
+}
+
+func TestNewClientWithMultipleMetaServers(t *testing.T) {
+	configServer := apollotest.NewServer()
+	defer configServer.Close()
+	configServer.Publish(testAppId, DEFAULT_CLUSTER_NAME, "application", Configurations{"timeout": "100"})
+
+	meta := &metaTestServer{}
+	meta.setInstances(configServer.URL)
+	metaServer := httptest.NewServer(meta)
+	defer metaServer.Close()
+	downServer := httptest.NewServer(meta)
+	downServer.Close()
+
+	//第一个meta server不可用时使用下一个
+	client, err := NewClientWithMetaServer(downServer.URL+"/, "+metaServer.URL, testAppId, WithReachabilityCheck(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	configs, _, err := client.Configs("application").Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if configs.Configurations["timeout"] != "100" {
+		t.Fatal(fmt.Sprintf("unexpected configs: %v", configs))
+	}
+
+	metaServer.Close()
+	if err = client.Discovery.Refresh(testAppId, nil); err == nil {
+		t.Fatal("Refresh should return error when all meta servers are down")
+	}
+}
+
+func TestServiceDiscoveryRefresh(t *testing.T) {
+	meta := &metaTestServer{}
+	meta.setInstances("http://10.0.0.1:8080", "http://10.0.0.2:8080")
+	metaServer := httptest.NewServer(meta)
+	defer metaServer.Close()
+
+	discovery := NewServiceDiscovery(metaServer.URL)
+	discovery.RefreshInterval = time.Hour
+	urls, err := discovery.ConfigServerUrls("apollo-client-test", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(urls) != 2 || urls[0] != "http://10.0.0.1:8080" {
+		t.Fatal(fmt.Sprintf("unexpected urls: %v", urls))
+	}
+
+	//未到刷新间隔不会请求meta server
+	meta.setInstances("http://10.0.0.3:8080")
+	if urls, _ = discovery.ConfigServerUrls("apollo-client-test", nil); len(urls) != 2 {
+		t.Fatal(fmt.Sprintf("urls should not be refreshed before interval: %v", urls))
+	}
+
+	//超过刷新间隔后在后台重新拉取实例列表
+	discovery.RefreshInterval = time.Nanosecond
+	deadline := time.Now().Add(time.Second)
+	for urls, _ = discovery.ConfigServerUrls("apollo-client-test", nil); len(urls) != 1 && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+		urls, _ = discovery.ConfigServerUrls("apollo-client-test", nil)
+	}
+	if len(urls) != 1 || urls[0] != "http://10.0.0.3:8080" {
+		t.Fatal(fmt.Sprintf("urls should be refreshed after interval: %v", urls))
+	}
+
+	//刷新失败时继续使用旧的实例列表
+	metaServer.Close()
+	if urls, err = discovery.ConfigServerUrls("apollo-client-test", nil); err != nil || len(urls) != 1 {
+		t.Fatal(fmt.Sprintf("stale urls should be used when refresh failed: %v, %v", urls, err))
+	}
+}
+
+func TestServiceDiscoveryRefreshFailure(t *testing.T) {
+	var requests, down int32
+	meta := &metaTestServer{}
+	meta.setInstances("http://10.0.0.1:8080")
+	metaServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if atomic.LoadInt32(&down) == 1 {
+			time.Sleep(200 * time.Millisecond)
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		meta.ServeHTTP(w, r)
+	}))
+	defer metaServer.Close()
+
+	discovery := NewServiceDiscovery(metaServer.URL)
+	discovery.RefreshInterval = time.Hour
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := discovery.ConfigServerUrls("apollo-client-test", nil); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if atomic.LoadInt32(&requests) != 1 {
+		t.Fatal(fmt.Sprintf("concurrent first refresh should be collapsed, requests: %d", requests))
+	}
+
+	//meta server不可用时使用旧的列表，不阻塞请求，并发请求只会触发一次后台刷新
+	atomic.StoreInt32(&down, 1)
+	discovery.mu.Lock()
+	discovery.attemptedAt = time.Time{}
+	discovery.mu.Unlock()
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		if urls, err := discovery.ConfigServerUrls("apollo-client-test", nil); err != nil || len(urls) != 1 {
+			t.Fatal(fmt.Sprintf("stale urls should be used when meta server is down: %v, %v", urls, err))
+		}
+	}
+	if elapsed := time.Since(start); elapsed >= 200*time.Millisecond {
+		t.Fatal(fmt.Sprintf("requests should not wait for meta server, elapsed: %v", elapsed))
+	}
+	time.Sleep(300 * time.Millisecond)
+	if atomic.LoadInt32(&requests) != 2 {
+		t.Fatal(fmt.Sprintf("stale refresh should be collapsed, requests: %d", requests))
+	}
+
+	//失败的刷新也会记录时间，刷新间隔内不再请求meta server
+	if _, err := discovery.ConfigServerUrls("apollo-client-test", nil); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if atomic.LoadInt32(&requests) != 2 {
+		t.Fatal(fmt.Sprintf("failed refresh should be recorded, requests: %d", requests))
+	}
+}
+
+func TestNewClientWithApolloTestMetaServer(t *testing.T) {
+	server := apollotest.NewServer()
+	defer server.Close()
+	server.Publish(testAppId, DEFAULT_CLUSTER_NAME, "application", Configurations{"timeout": "100"})
+
+	client, err := NewClientWithMetaServer(server.URL, testAppId, WithReachabilityCheck(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = client.Configs("application").Get(); err != nil {
+		t.Fatal(err)
+	}
+	if server.RequestCount("/services/config") != 1 {
+		t.Fatal("config service instances should be discovered from meta server")
+	}
+}
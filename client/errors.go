@@ -0,0 +1,17 @@
+package client
+
+import "github.com/flylan/apollo-config-lib/request"
+
+// 与request包共用的错误，可以通过 errors.Is 或 errors.As 判断
+var (
+	//config service返回404，表示namespace不存在
+	ErrNamespaceNotFound = request.ErrNotFound
+	//返回401，表示签名校验失败（秘钥错误或时间偏差过大）
+	ErrUnauthorized = request.ErrUnauthorized
+	//响应体无法解析
+	ErrInvalidResponse = request.ErrInvalidResponse
+)
+
+type HTTPError = request.HTTPError
+
+type DecodeError = request.DecodeError
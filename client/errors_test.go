@@ -0,0 +1,69 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/flylan/apollo-config-lib/apollotest"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestErrors(t *testing.T) {
+	server := apollotest.NewServer()
+	defer server.Close()
+	server.Publish(testAppId, DEFAULT_CLUSTER_NAME, "application", Configurations{"timeout": "100"})
+
+	client, err := NewClient(server.URL, testAppId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var httpErr *HTTPError
+	_, _, err = client.Configs("not-exists").Get()
+	if !errors.Is(err, ErrNamespaceNotFound) || !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusNotFound {
+		t.Fatal(fmt.Sprintf("missing namespace should return ErrNamespaceNotFound, err: %v", err))
+	}
+	configsParam := client.Configs("not-exists")
+	configsParam.UseNoCacheApi = false
+	if _, _, err = configsParam.Get(); !errors.Is(err, ErrNamespaceNotFound) {
+		t.Fatal(fmt.Sprintf("missing namespace should return ErrNamespaceNotFound, err: %v", err))
+	}
+
+	server.SetSecret(testAppId, testSecret)
+	if _, _, err = client.Configs("application").Get(); !errors.Is(err, ErrUnauthorized) {
+		t.Fatal(fmt.Sprintf("bad signature should return ErrUnauthorized, err: %v", err))
+	}
+	if _, _, err = client.Notifications("application").Get(); !errors.Is(err, ErrUnauthorized) {
+		t.Fatal(fmt.Sprintf("bad signature should return ErrUnauthorized, err: %v", err))
+	}
+}
+
+func TestErrorsInvalidResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("ip") == "10.0.0.1" {
+			time.Sleep(time.Second)
+		}
+		_, _ = w.Write([]byte("{invalid"))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, testAppId, WithIp([]byte{127, 0, 0, 1}), WithRequestTimeout(100*time.Millisecond, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decodeErr *DecodeError
+	if _, _, err = client.Configs("application").Get(); !errors.Is(err, ErrInvalidResponse) || !errors.As(err, &decodeErr) {
+		t.Fatal(fmt.Sprintf("invalid json should return ErrInvalidResponse, err: %v", err))
+	}
+	if _, _, err = client.Notifications("application").Get(); !errors.Is(err, ErrInvalidResponse) {
+		t.Fatal(fmt.Sprintf("invalid json should return ErrInvalidResponse, err: %v", err))
+	}
+
+	configsParam := client.Configs("application")
+	configsParam.Ip = []byte{10, 0, 0, 1}
+	if _, _, err = configsParam.GetWithContext(context.Background()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal(fmt.Sprintf("request timeout should return context.DeadlineExceeded, err: %v", err))
+	}
+}
@@ -0,0 +1,263 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	FORMAT_PROPERTIES = "properties"
+	FORMAT_XML        = "xml"
+	FORMAT_JSON       = "json"
+	FORMAT_YML        = "yml"
+	FORMAT_YAML       = "yaml"
+	FORMAT_TXT        = "txt"
+
+	//非properties格式的namespace，整个文件内容保存在该key中
+	CONTENT_KEY = "content"
+)
+
+// 将namespace文件内容解析为文档
+type Parser interface {
+	Parse(content string) (map[string]interface{}, error)
+}
+
+type ParserFunc func(content string) (map[string]interface{}, error)
+
+var (
+	parsersMu sync.RWMutex
+	parsers   = map[string]Parser{
+		FORMAT_JSON: ParserFunc(parseJSON),
+		FORMAT_YML:  ParserFunc(parseYAML),
+		FORMAT_YAML: ParserFunc(parseYAML),
+		FORMAT_XML:  ParserFunc(parseXML),
+	}
+)
+
+func (f ParserFunc) Parse(content string) (map[string]interface{}, error) {
+	return f(content)
+}
+
+// 注册（或替换）某种格式的解析器，format为namespace的后缀，如 yaml、toml
+func RegisterParser(format string, parser Parser) {
+	parsersMu.Lock()
+	defer parsersMu.Unlock()
+	parsers[strings.ToLower(format)] = parser
+}
+
+func getParser(format string) (Parser, bool) {
+	parsersMu.RLock()
+	defer parsersMu.RUnlock()
+	parser, ok := parsers[format]
+	return parser, ok
+}
+
+// 根据namespace名称的后缀判断格式，没有后缀或后缀无法识别时为properties
+func NamespaceFormat(namespaceName string) string {
+	i := strings.LastIndex(namespaceName, ".")
+	if i < 0 {
+		return FORMAT_PROPERTIES
+	}
+	format := strings.ToLower(namespaceName[i+1:])
+	if _, ok := getParser(format); ok || format == FORMAT_TXT || format == FORMAT_PROPERTIES {
+		return format
+	}
+	return FORMAT_PROPERTIES
+}
+
+// 获取namespace的格式
+func (cp *ConfigsParam) Format() string {
+	return NamespaceFormat(cp.NamespaceName)
+}
+
+// 获取namespace的格式
+func (c *Configs) Format() string {
+	return NamespaceFormat(c.NamespaceName)
+}
+
+// 获取非properties格式namespace的文件内容
+func (c *Configs) Content() string {
+	return c.Configurations[CONTENT_KEY]
+}
+
+// 解析namespace为文档，properties格式直接返回所有key，txt格式返回 content
+func (c *Configs) Document() (map[string]interface{}, error) {
+	parser, ok := getParser(c.Format())
+	if !ok {
+		document := make(map[string]interface{}, len(c.Configurations))
+		for key, value := range c.Configurations {
+			document[key] = value
+		}
+		return document, nil
+	}
+	document, err := parser.Parse(c.Content())
+	if err != nil {
+		return nil, fmt.Errorf("Unable to parse namespace %s as %s: %w", c.NamespaceName, c.Format(), err)
+	}
+	return document, nil
+}
+
+// 解析namespace并展开为扁平的key，嵌套的key以 . 连接，数组元素为 key[i]，
+// 元素都是标量的数组还会以逗号连接保存在 key 中，以便按切片读取
+func (c *Configs) Flatten() (Configurations, error) {
+	if _, ok := getParser(c.Format()); !ok {
+		flattened := make(Configurations, len(c.Configurations))
+		for key, value := range c.Configurations {
+			flattened[key] = value
+		}
+		return flattened, nil
+	}
+	document, err := c.Document()
+	if err != nil {
+		return nil, err
+	}
+	flattened := Configurations{}
+	flatten(flattened, "", document)
+	return flattened, nil
+}
+
+// 获取展开后配置的类型化读取实例
+func (c *Configs) Config() (*Config, error) {
+	flattened, err := c.Flatten()
+	if err != nil {
+		return nil, err
+	}
+	return NewConfig(flattened), nil
+}
+
+func flatten(flattened Configurations, key string, value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, item := range v {
+			flatten(flattened, joinKey(key, k), item)
+		}
+	case map[interface{}]interface{}:
+		for k, item := range v {
+			flatten(flattened, joinKey(key, fmt.Sprint(k)), item)
+		}
+	case []interface{}:
+		scalars := make([]string, 0, len(v))
+		for i, item := range v {
+			flatten(flattened, fmt.Sprintf("%s[%d]", key, i), item)
+			if s, ok := scalarString(item); ok {
+				scalars = append(scalars, s)
+			}
+		}
+		if len(scalars) == len(v) && key != "" {
+			flattened[key] = strings.Join(scalars, DEFAULT_SLICE_SEPARATOR)
+		}
+	default:
+		if s, ok := scalarString(v); ok && key != "" {
+			flattened[key] = s
+		}
+	}
+}
+
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + KEY_SEPARATOR + key
+}
+
+// 标量转换为字符串，浮点数不使用科学计数法
+func scalarString(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case nil:
+		return "", true
+	case string:
+		return v, true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), true
+	case map[string]interface{}, map[interface{}]interface{}, []interface{}:
+		return "", false
+	default:
+		return fmt.Sprint(v), true
+	}
+}
+
+func parseJSON(content string) (map[string]interface{}, error) {
+	decoder := json.NewDecoder(strings.NewReader(content))
+	decoder.UseNumber()
+	document := map[string]interface{}{}
+	if err := decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+	return document, nil
+}
+
+func parseYAML(content string) (map[string]interface{}, error) {
+	document := map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(content), &document); err != nil {
+		return nil, err
+	}
+	return document, nil
+}
+
+// 解析xml，元素名作为key，只有文本的元素值为文本，同名的兄弟元素合并为数组，属性与子元素同级
+func parseXML(content string) (map[string]interface{}, error) {
+	decoder := xml.NewDecoder(strings.NewReader(content))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil, errors.New("no root element")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			value, err := parseXMLElement(decoder, start)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]interface{}{start.Name.Local: value}, nil
+		}
+	}
+}
+
+func parseXMLElement(decoder *xml.Decoder, start xml.StartElement) (interface{}, error) {
+	children := map[string]interface{}{}
+	for _, attr := range start.Attr {
+		children[attr.Name.Local] = attr.Value
+	}
+	var text bytes.Buffer
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			value, err := parseXMLElement(decoder, t)
+			if err != nil {
+				return nil, err
+			}
+			name := t.Name.Local
+			switch existing := children[name].(type) {
+			case nil:
+				children[name] = value
+			case []interface{}:
+				children[name] = append(existing, value)
+			default:
+				children[name] = []interface{}{existing, value}
+			}
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			if len(children) == 0 {
+				return strings.TrimSpace(text.String()), nil
+			}
+			return children, nil
+		}
+	}
+}
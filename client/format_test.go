@@ -0,0 +1,95 @@
+package client
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNamespaceFormat(t *testing.T) {
+	cases := map[string]string{
+		"application":     FORMAT_PROPERTIES,
+		"app.properties":  FORMAT_PROPERTIES,
+		"app.yaml":        FORMAT_YAML,
+		"app.YML":         FORMAT_YML,
+		"app.json":        FORMAT_JSON,
+		"app.xml":         FORMAT_XML,
+		"app.txt":         FORMAT_TXT,
+		"TEST1.apollo-ns": FORMAT_PROPERTIES,
+	}
+	for namespaceName, format := range cases {
+		if res := NamespaceFormat(namespaceName); res != format {
+			t.Fatal(fmt.Sprintf("unexpected format of %s: %s", namespaceName, res))
+		}
+	}
+}
+
+func TestConfigsFlatten(t *testing.T) {
+	cases := map[string]string{
+		"app.yaml": "server:\n  port: 8080\n  ratio: 1000000.5\nhosts:\n  - a\n  - b\nusers:\n  - name: tom\n",
+		"app.json": `{"server":{"port":8080,"ratio":1000000.5},"hosts":["a","b"],"users":[{"name":"tom"}]}`,
+		"app.xml":  `<app><server port="8080"><ratio>1000000.5</ratio></server><hosts>a</hosts><hosts>b</hosts><users><name>tom</name></users></app>`,
+	}
+	for namespaceName, content := range cases {
+		configs := &Configs{NamespaceName: namespaceName, Configurations: Configurations{CONTENT_KEY: content}}
+		flattened, err := configs.Flatten()
+		if err != nil {
+			t.Fatal(err)
+		}
+		prefix := ""
+		if configs.Format() == FORMAT_XML {
+			prefix = "app."
+		}
+		expect := Configurations{
+			prefix + "server.port":   "8080",
+			prefix + "server.ratio":  "1000000.5",
+			prefix + "hosts":         "a,b",
+			prefix + "hosts[0]":      "a",
+			prefix + "hosts[1]":      "b",
+			prefix + "users[0].name": "tom",
+		}
+		if configs.Format() == FORMAT_XML {
+			expect["app.users.name"] = "tom"
+			delete(expect, "app.users[0].name")
+		}
+		if !reflect.DeepEqual(flattened, expect) {
+			t.Fatal(fmt.Sprintf("unexpected flattened configurations of %s: %v", namespaceName, flattened))
+		}
+
+		config, err := configs.Config()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if port, err := config.GetInt(prefix + "server.port"); err != nil || port != 8080 {
+			t.Fatal(fmt.Sprintf("GetInt returns wrong result of %s: %d, err: %v", namespaceName, port, err))
+		}
+	}
+
+	configs := &Configs{NamespaceName: "app.yaml", Configurations: Configurations{CONTENT_KEY: "a: [b"}}
+	if _, err := configs.Document(); err == nil {
+		t.Fatal("Document should return error when content is invalid")
+	}
+	configs = &Configs{NamespaceName: "app.txt", Configurations: Configurations{CONTENT_KEY: "hello"}}
+	if flattened, err := configs.Flatten(); err != nil || flattened[CONTENT_KEY] != "hello" {
+		t.Fatal(fmt.Sprintf("txt namespace should keep content, flattened: %v, err: %v", flattened, err))
+	}
+}
+
+func TestRegisterParser(t *testing.T) {
+	RegisterParser("INI", ParserFunc(func(content string) (map[string]interface{}, error) {
+		document := map[string]interface{}{}
+		for _, line := range strings.Split(content, "\n") {
+			if kv := strings.SplitN(line, "=", 2); len(kv) == 2 {
+				document[kv[0]] = kv[1]
+			}
+		}
+		return document, nil
+	}))
+
+	repository := NewRepository()
+	repository.Set(&Configs{NamespaceName: "app.ini", Configurations: Configurations{CONTENT_KEY: "a=1\nb=2"}})
+	if value, err := repository.Config("app.ini").GetInt("b"); err != nil || value != 2 {
+		t.Fatal(fmt.Sprintf("Repository should read flattened configurations, value: %d, err: %v", value, err))
+	}
+}
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,9 +12,14 @@ import (
 
 const DEFAULT_NOTIFICATION_ID = -1
 
+type NotificationMessages struct {
+	Details map[string]int64 `json:"details"`
+}
+
 type Notification struct {
-	NamespaceName  string `json:"namespaceName"`
-	NotificationId int64  `json:"notificationId"`
+	NamespaceName  string                `json:"namespaceName"`
+	NotificationId int64                 `json:"notificationId"`
+	Messages       *NotificationMessages `json:"messages,omitempty"`
 }
 
 type Notifications []Notification
@@ -41,6 +47,11 @@ func (c *Client) Notifications(a interface{}) *NotificationsParam {
 
 // 应用感知配置更新
 func (np *NotificationsParam) Get() (*Notifications, *request.Info, error) {
+	return np.GetWithContext(context.Background())
+}
+
+// 应用感知配置更新，支持通过ctx取消长轮询
+func (np *NotificationsParam) GetWithContext(ctx context.Context) (*Notifications, *request.Info, error) {
 	//初始化
 	info := &request.Info{}
 
@@ -49,7 +60,7 @@ func (np *NotificationsParam) Get() (*Notifications, *request.Info, error) {
 	}
 
 	// 将map转换为JSON字符串
-	notifications := make(Notifications, len(np.NotificationsMap))
+	notifications := make(Notifications, 0, len(np.NotificationsMap))
 	for namespaceName, notificationId := range np.NotificationsMap {
 		notifications = append(notifications, Notification{NamespaceName: namespaceName, NotificationId: notificationId})
 	}
@@ -61,37 +72,41 @@ func (np *NotificationsParam) Get() (*Notifications, *request.Info, error) {
 	// 清空切片内容
 	notifications = notifications[:0]
 
-	//构建请求链接
-	requestUrl := fmt.Sprintf(
-		"%s/notifications/v2?appId=%s&cluster=%s&notifications=%s",
-		np.Client.ConfigServerUrl,
+	//构建请求路径
+	requestPath := fmt.Sprintf(
+		"/notifications/v2?appId=%s&cluster=%s&notifications=%s",
 		np.Client.AppId,
 		np.Client.ClusterName,
 		url.QueryEscape(string(nj)),
 	)
 
 	//发送get请求
-	info, err = request.SendGetRequest(
-		requestUrl,
-		np.Client.AppId,
-		np.Client.Secret,
+	info, err = np.Client.sendGetRequest(
+		ctx,
+		func(configServerUrl string) string { return configServerUrl + requestPath },
 		np.Client.RequestTimeout.GetNotifications,
+		true,
 		info,
 	)
 	if err != nil {
 		return nil, info, err
 	}
 
+	//长轮询超时无变更，返回空结果
+	if info.IsDataNotModified() {
+		return &notifications, info, nil
+	}
+
 	//请求失败
 	if !info.IsGetDataSuccess() {
-		return nil, info, errors.New(string(info.ResponseBody))
+		return nil, info, request.NewHTTPError(info)
 	}
 
 	//转换json字符串为结构体
 	if !utils.IsByteSliceEmpty(info.ResponseBody) {
 		err = json.Unmarshal(info.ResponseBody, &notifications)
 		if err != nil {
-			return nil, info, err
+			return nil, info, request.NewDecodeError(info, err)
 		}
 	}
 
@@ -51,7 +51,7 @@ func checkNotifications(t *testing.T, a interface{}) {
 }
 
 func testNotificationsGet(t *testing.T, a interface{}) (*Client, *Notifications, *request.Info) {
-	client := testGetClient(t)
+	client := testNewClient(t)
 	notifications, info, err := client.Notifications(a).Get()
 	if err != nil {
 		t.Fatal(err)
@@ -0,0 +1,146 @@
+package client
+
+import (
+	"github.com/flylan/apollo-config-lib/request"
+	"net"
+	"net/http"
+	"time"
+)
+
+// 日志接口，标准库的*log.Logger即满足该接口
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// 客户端构建选项
+type Option func(c *Client)
+
+// 指定集群名称，默认为default
+func WithCluster(clusterName string) Option {
+	return func(c *Client) {
+		c.ClusterName = clusterName
+	}
+}
+
+// 指定访问秘钥
+func WithSecret(secret string) Option {
+	return func(c *Client) {
+		c.Secret = secret
+	}
+}
+
+// 指定多个访问秘钥（按优先级排序），用于秘钥轮换，请求返回401时会依次使用下一个秘钥重试
+func WithSecrets(secrets ...string) Option {
+	return func(c *Client) {
+		c.SetSecrets(secrets...)
+	}
+}
+
+// 指定额外的请求签名器，在Apollo签名之后执行，可用于网关鉴权（Bearer Token、自定义请求头、请求ID等），
+// 不需要Apollo签名时不配置秘钥即可
+func WithSigner(signer request.Signer) Option {
+	return func(c *Client) {
+		c.Signer = signer
+	}
+}
+
+// 指定获取配置和长轮询的请求超时时间，小于等于0时保持默认值
+func WithRequestTimeout(getConfigs, getNotifications time.Duration) Option {
+	return func(c *Client) {
+		if getConfigs > 0 {
+			c.RequestTimeout.GetConfigs = getConfigs
+		}
+		if getNotifications > 0 {
+			c.RequestTimeout.GetNotifications = getNotifications
+		}
+	}
+}
+
+// 指定应用部署的机器ip，用于灰度发布，默认自动探测出网ip
+func WithIp(ip net.IP) Option {
+	return func(c *Client) {
+		c.Ip = ip
+	}
+}
+
+// 指定灰度配置的标签
+func WithLabel(label string) Option {
+	return func(c *Client) {
+		c.Label = label
+	}
+}
+
+// 指定发送请求使用的http.Client
+func WithHttpClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.HttpClient = httpClient
+	}
+}
+
+// 指定日志输出
+func WithLogger(logger Logger) Option {
+	return func(c *Client) {
+		c.Logger = logger
+	}
+}
+
+// 指定本地缓存
+func WithCache(cache Cache) Option {
+	return func(c *Client) {
+		c.Cache = cache
+	}
+}
+
+// 指定负载均衡策略
+func WithBalancer(balancer Balancer) Option {
+	return func(c *Client) {
+		c.Balancer = balancer
+	}
+}
+
+// 指定重试策略
+func WithRetryPolicy(retryPolicy *RetryPolicy) Option {
+	return func(c *Client) {
+		c.RetryPolicy = retryPolicy
+	}
+}
+
+// 指定解密器，通过Config等读取配置时自动解密 ENC(...) 格式的值，本地缓存中保存的仍是密文
+func WithDecryptor(decryptor Decryptor) Option {
+	return func(c *Client) {
+		c.Decryptor = decryptor
+	}
+}
+
+// 构建客户端时检测config service（或meta server）是否可以访问，不可访问时构建失败
+func WithReachabilityCheck(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.probeTimeout = timeout
+	}
+}
+
+// 指定发送请求使用的RoundTripper，可用于mTLS、代理、监控埋点等，会覆盖WithHttpClient
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *Client) {
+		c.HttpClient = request.NewHttpClient(transport)
+	}
+}
+
+// 指定TLS配置（根证书、客户端证书、服务端名称、最低TLS版本等），不能与WithHttpClient、WithTransport同时使用
+func WithTLSConfig(tlsConfig *request.TLSConfig) Option {
+	return func(c *Client) {
+		c.TLSConfig = tlsConfig
+	}
+}
+
+// 跳过服务端证书校验，仅用于测试环境
+func WithInsecureSkipVerify() Option {
+	return func(c *Client) {
+		tlsConfig := request.TLSConfig{}
+		if c.TLSConfig != nil {
+			tlsConfig = *c.TLSConfig
+		}
+		tlsConfig.InsecureSkipVerify = true
+		c.TLSConfig = &tlsConfig
+	}
+}
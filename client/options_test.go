@@ -0,0 +1,141 @@
+package client
+
+import (
+	"crypto/x509"
+	"fmt"
+	"github.com/flylan/apollo-config-lib/apollotest"
+	"github.com/flylan/apollo-config-lib/request"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewClientOptions(t *testing.T) {
+	var logs strings.Builder
+	httpClient := &http.Client{}
+	cache := NewFileCache(t.TempDir())
+	client, err := NewClient(
+		"http://127.0.0.1:1",
+		"apollo-client-test",
+		WithCluster("gray"),
+		WithSecret("4081edabfe4e4ba097cc16defc526c2f"),
+		WithRequestTimeout(time.Second, 0),
+		WithIp(net.ParseIP("10.0.0.1")),
+		WithLabel("canary"),
+		WithHttpClient(httpClient),
+		WithLogger(log.New(&logs, "", 0)),
+		WithCache(cache),
+		WithBalancer(&StickyBalancer{}),
+		WithRetryPolicy(DefaultRetryPolicy()),
+	)
+	if err != nil {
+		t.Fatal(fmt.Sprintf("NewClient should not probe server by default, err: %v", err))
+	}
+	if client.ClusterName != "gray" || client.Secret == "" || client.Label != "canary" || client.Cache != cache ||
+		client.HttpClient != httpClient || client.RetryPolicy == nil || client.Balancer == nil {
+		t.Fatal(fmt.Sprintf("options are not applied: %v", client))
+	}
+	if client.RequestTimeout.GetConfigs != time.Second || client.RequestTimeout.GetNotifications != DEFAULT_GET_NOTIFICATIONS_TIMEOUT {
+		t.Fatal(fmt.Sprintf("unexpected RequestTimeout: %v", client.RequestTimeout))
+	}
+
+	cp := client.Configs("application")
+	if cp.Ip.String() != "10.0.0.1" || cp.Label != "canary" {
+		t.Fatal(fmt.Sprintf("Configs should use client Ip and Label, ip: %s, label: %s", cp.Ip, cp.Label))
+	}
+
+	client.logf("hello %s", "apollo")
+	if logs.String() != "hello apollo\n" {
+		t.Fatal(fmt.Sprintf("unexpected logs: %s", logs.String()))
+	}
+}
+
+func TestWithHttpClient(t *testing.T) {
+	server := apollotest.NewServer()
+	defer server.Close()
+	server.Publish(testAppId, DEFAULT_CLUSTER_NAME, "application", Configurations{"timeout": "100"})
+
+	var requests int
+	httpClient := &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		requests++
+		return http.DefaultTransport.RoundTrip(r)
+	})}
+	client, err := NewClient(server.URL, "apollo-client-test", WithHttpClient(httpClient), WithReachabilityCheck(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = client.Configs("application").Get(); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 1 {
+		t.Fatal(fmt.Sprintf("request should be sent with injected http.Client, requests: %d", requests))
+	}
+}
+
+func TestWithTransport(t *testing.T) {
+	server := apollotest.NewServer()
+	defer server.Close()
+	server.Publish(testAppId, DEFAULT_CLUSTER_NAME, "application", Configurations{"timeout": "100"})
+
+	var requests int
+	transport := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		requests++
+		return http.DefaultTransport.RoundTrip(r)
+	})
+	client, err := NewClient(server.URL, "apollo-client-test", WithTransport(transport))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = client.Notifications("application").Get(); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 1 {
+		t.Fatal(fmt.Sprintf("request should be sent with injected transport, requests: %d", requests))
+	}
+}
+
+type roundTripperFunc func(r *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestWithTLSConfig(t *testing.T) {
+	server := apollotest.NewTLSServer()
+	defer server.Close()
+	server.Publish(testAppId, DEFAULT_CLUSTER_NAME, "application", Configurations{"timeout": "100"})
+
+	client, err := NewClient(server.URL, "apollo-client-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = client.Configs("application").Get(); err == nil {
+		t.Fatal("Get should verify server certificate by default")
+	}
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(server.HttpServer().Certificate())
+	tlsConfig := &request.TLSConfig{RootCAs: rootCAs}
+	client, err = NewClient(server.URL, "apollo-client-test", WithTLSConfig(tlsConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = client.Configs("application").Get(); err != nil {
+		t.Fatal(err)
+	}
+
+	client, err = NewClient(server.URL, "apollo-client-test", WithTLSConfig(tlsConfig), WithInsecureSkipVerify())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tlsConfig.InsecureSkipVerify || !client.TLSConfig.InsecureSkipVerify {
+		t.Fatal("WithInsecureSkipVerify should not modify the given TLSConfig")
+	}
+
+	if _, err = NewClient(server.URL, "apollo-client-test", WithTLSConfig(tlsConfig), WithHttpClient(&http.Client{})); err == nil {
+		t.Fatal("NewClient should return error when TLSConfig is used together with HttpClient")
+	}
+}
@@ -0,0 +1,151 @@
+package client
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	ENV_OVERRIDE_PREFIX = "APOLLO_OVERRIDE_"
+
+	PROVENANCE_OVERRIDE = "override"
+	PROVENANCE_ENV      = "env"
+)
+
+// 可以返回配置值所在namespace的配置值来源
+type namespaceLookup interface {
+	LookupWithNamespace(key string) (string, string, bool)
+}
+
+// 覆盖在Apollo配置之上的配置层，用于本地调试或紧急覆盖
+//
+// 优先级从高到低依次为：手动设置的覆盖值、APOLLO_OVERRIDE_<KEY> 环境变量、Apollo配置，
+// 环境变量名由key转为大写并将字母数字以外的字符替换为下划线得到，如 db.host 对应 APOLLO_OVERRIDE_DB_HOST
+type Overlay struct {
+	base      Source
+	mu        sync.RWMutex
+	overrides map[string]string
+}
+
+// 在base之上构建覆盖层，overrides为手动设置的覆盖值（如命令行参数）
+func NewOverlay(base Source, overrides map[string]string) *Overlay {
+	o := &Overlay{base: base, overrides: make(map[string]string, len(overrides))}
+	for key, value := range overrides {
+		o.overrides[key] = value
+	}
+	return o
+}
+
+// 在客户端多个namespace组成的分层视图（同 Composite）之上构建覆盖层，读取时总是使用最新的配置
+func (c *Client) Overlay(overrides map[string]string, namespaceNames ...string) *Overlay {
+	return NewOverlay(c.newComposite(namespaceNames...), overrides)
+}
+
+// 设置覆盖值
+func (o *Overlay) Set(key, value string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.overrides[key] = value
+}
+
+// 删除覆盖值
+func (o *Overlay) Delete(key string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	delete(o.overrides, key)
+}
+
+// 按优先级查找配置值
+func (o *Overlay) Lookup(key string) (string, bool) {
+	value, _, ok := o.LookupWithProvenance(key)
+	return value, ok
+}
+
+// 按优先级查找配置值，同时返回生效值的来源：override、env或所在的namespace名称
+// （base无法提供namespace名称时为空字符串）
+func (o *Overlay) LookupWithProvenance(key string) (string, string, bool) {
+	o.mu.RLock()
+	value, ok := o.overrides[key]
+	o.mu.RUnlock()
+	if ok {
+		return value, PROVENANCE_OVERRIDE, true
+	}
+	if value, ok = os.LookupEnv(EnvOverrideName(key)); ok {
+		return value, PROVENANCE_ENV, true
+	}
+	if base, ok := o.base.(namespaceLookup); ok {
+		return base.LookupWithNamespace(key)
+	}
+	value, ok = o.base.Lookup(key)
+	return value, "", ok
+}
+
+// 按优先级查找配置值，key不存在时返回ErrKeyNotFound，base读取失败（如解密失败）时返回对应的错误
+func (o *Overlay) Resolve(key string) (string, error) {
+	o.mu.RLock()
+	value, ok := o.overrides[key]
+	o.mu.RUnlock()
+	if ok {
+		return value, nil
+	}
+	if value, ok = os.LookupEnv(EnvOverrideName(key)); ok {
+		return value, nil
+	}
+	value, ok, err := lookup(o.base, key)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrKeyNotFound, key)
+	}
+	return value, nil
+}
+
+// 获取生效值的来源，key不存在时返回false
+func (o *Overlay) Provenance(key string) (string, bool) {
+	_, provenance, ok := o.LookupWithProvenance(key)
+	return provenance, ok
+}
+
+// 获取base和覆盖值中的key（已排序），只存在于环境变量中的key无法还原，不包含在内
+func (o *Overlay) Keys() []string {
+	seen := map[string]bool{}
+	keys := make([]string, 0)
+	if base, ok := o.base.(KeysSource); ok {
+		for _, key := range base.Keys() {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	o.mu.RLock()
+	for key := range o.overrides {
+		if !seen[key] {
+			keys = append(keys, key)
+		}
+	}
+	o.mu.RUnlock()
+	sort.Strings(keys)
+	return keys
+}
+
+// 获取类型化读取实例
+func (o *Overlay) Config() *Config {
+	return NewConfig(o)
+}
+
+// 获取key对应的覆盖环境变量名
+func EnvOverrideName(key string) string {
+	return ENV_OVERRIDE_PREFIX + strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, key)
+}
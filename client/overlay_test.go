@@ -0,0 +1,67 @@
+package client
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestEnvOverrideName(t *testing.T) {
+	cases := map[string]string{
+		"timeout":       "APOLLO_OVERRIDE_TIMEOUT",
+		"db.host":       "APOLLO_OVERRIDE_DB_HOST",
+		"server-port_1": "APOLLO_OVERRIDE_SERVER_PORT_1",
+	}
+	for key, name := range cases {
+		if res := EnvOverrideName(key); res != name {
+			t.Fatal(fmt.Sprintf("unexpected env name of %s: %s", key, res))
+		}
+	}
+}
+
+func TestOverlay(t *testing.T) {
+	client := &Client{}
+	client.applyConfigs(&Configs{NamespaceName: "application", Configurations: Configurations{"timeout": "100", "db.host": "10.0.0.1"}})
+	client.applyConfigs(&Configs{NamespaceName: "TEAM.test_case_1", Configurations: Configurations{"retry": "3", "db.port": "3306"}})
+	t.Setenv("APOLLO_OVERRIDE_DB_HOST", "127.0.0.1")
+	t.Setenv("APOLLO_OVERRIDE_RETRY", "5")
+
+	overlay := client.Overlay(map[string]string{"retry": "10", "debug": "true"}, "application", "TEAM.test_case_1")
+	if len(client.changes.listeners) != 0 {
+		t.Fatal("Overlay should not register change listener")
+	}
+	cases := []struct {
+		key, value, provenance string
+	}{
+		{"retry", "10", PROVENANCE_OVERRIDE},
+		{"debug", "true", PROVENANCE_OVERRIDE},
+		{"db.host", "127.0.0.1", PROVENANCE_ENV},
+		{"timeout", "100", "application"},
+		{"db.port", "3306", "TEAM.test_case_1"},
+	}
+	for _, c := range cases {
+		value, provenance, ok := overlay.LookupWithProvenance(c.key)
+		if !ok || value != c.value || provenance != c.provenance {
+			t.Fatal(fmt.Sprintf("unexpected lookup result of %s: %s %s", c.key, value, provenance))
+		}
+	}
+	if _, ok := overlay.Provenance("not-exists"); ok {
+		t.Fatal("Provenance should return false when key does not exist")
+	}
+	if !reflect.DeepEqual(overlay.Keys(), []string{"db.host", "db.port", "debug", "retry", "timeout"}) {
+		t.Fatal(fmt.Sprintf("unexpected keys: %v", overlay.Keys()))
+	}
+
+	overlay.Delete("retry")
+	if retry, err := overlay.Config().GetInt("retry"); err != nil || retry != 5 {
+		t.Fatal(fmt.Sprintf("env should take effect after override deleted, retry: %d, err: %v", retry, err))
+	}
+	overlay.Set("timeout", "200")
+	if provenance, _ := overlay.Provenance("timeout"); provenance != PROVENANCE_OVERRIDE {
+		t.Fatal(fmt.Sprintf("unexpected provenance: %s", provenance))
+	}
+
+	if _, provenance, _ := NewOverlay(Configurations{"a": "1"}, nil).LookupWithProvenance("a"); provenance != "" {
+		t.Fatal(fmt.Sprintf("provenance of source without namespace should be empty: %s", provenance))
+	}
+}
@@ -0,0 +1,143 @@
+package client
+
+import (
+	"sort"
+	"sync"
+)
+
+type Repository struct {
+	mu      sync.RWMutex
+	configs map[string]*Configs
+	//非properties格式namespace展开后的配置
+	flattened map[string]Configurations
+}
+
+// 仓库中某个namespace的配置来源，每次读取都取最新的配置
+type namespaceSource struct {
+	repository    *Repository
+	namespaceName string
+}
+
+// 构建一个内存配置仓库
+func NewRepository() *Repository {
+	return &Repository{configs: map[string]*Configs{}, flattened: map[string]Configurations{}}
+}
+
+// 保存namespace最新配置，返回与上一次配置的差异，yaml、json等格式的namespace按展开后的key对比，
+// 解析失败时继续使用上一次展开的配置
+func (r *Repository) Set(configs *Configs) *ChangeEvent {
+	event, _ := r.set(configs)
+	return event
+}
+
+// 保存namespace最新配置，同时返回yaml、json等格式namespace的解析错误
+func (r *Repository) set(configs *Configs) (*ChangeEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	oldFlattened, hasFlattened := r.flattened[configs.NamespaceName]
+	var oldConfigurations Configurations
+	if hasFlattened {
+		oldConfigurations = oldFlattened
+	} else if old, ok := r.configs[configs.NamespaceName]; ok {
+		oldConfigurations = old.Configurations
+	}
+	r.configs[configs.NamespaceName] = configs
+	delete(r.flattened, configs.NamespaceName)
+	newConfigurations := configs.Configurations
+	var err error
+	if _, ok := getParser(configs.Format()); ok {
+		var flattened Configurations
+		if flattened, err = configs.Flatten(); err != nil {
+			//解析失败时保留上一次展开的配置，避免所有key被当作删除，没有上一次的配置时读取不到任何key
+			flattened = oldFlattened
+			if flattened == nil {
+				flattened = Configurations{}
+			}
+		}
+		newConfigurations = flattened
+		r.flattened[configs.NamespaceName] = newConfigurations
+	}
+	event := diffConfigurations(configs.NamespaceName, oldConfigurations, newConfigurations)
+	event.ReleaseKey = configs.ReleaseKey
+	return event, err
+}
+
+// 获取namespace最新配置
+func (r *Repository) Get(namespaceName string) (*Configs, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	configs, ok := r.configs[namespaceName]
+	return configs, ok
+}
+
+// 获取仓库中所有的namespace名称（已排序）
+func (r *Repository) NamespaceNames() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	namespaceNames := make([]string, 0, len(r.configs))
+	for namespaceName := range r.configs {
+		namespaceNames = append(namespaceNames, namespaceName)
+	}
+	sort.Strings(namespaceNames)
+	return namespaceNames
+}
+
+// 获取namespace用于读取的配置，yaml、json等格式的namespace返回展开后的配置
+func (r *Repository) configurations(namespaceName string) (Configurations, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if flattened, ok := r.flattened[namespaceName]; ok {
+		return flattened, true
+	}
+	configs, ok := r.configs[namespaceName]
+	if !ok {
+		return nil, false
+	}
+	return configs.Configurations, true
+}
+
+// 获取namespace的类型化读取实例，读取时总是使用最新的配置，yaml、json等格式的namespace按展开后的key读取
+func (r *Repository) Config(namespaceName string) *Config {
+	return NewConfig(&namespaceSource{repository: r, namespaceName: namespaceName})
+}
+
+func (s *namespaceSource) Lookup(key string) (string, bool) {
+	configurations, ok := s.repository.configurations(s.namespaceName)
+	if !ok {
+		return "", false
+	}
+	return configurations.Lookup(key)
+}
+
+func (s *namespaceSource) Keys() []string {
+	configurations, ok := s.repository.configurations(s.namespaceName)
+	if !ok {
+		return nil
+	}
+	return configurations.Keys()
+}
+
+func (s *namespaceSource) LookupWithNamespace(key string) (string, string, bool) {
+	value, ok := s.Lookup(key)
+	return value, s.namespaceName, ok
+}
+
+// 获取客户端的配置仓库，Watcher拉取到的配置都会保存到这里
+func (c *Client) Repository() *Repository {
+	c.repositoryOnce.Do(func() {
+		if c.repository == nil {
+			c.repository = NewRepository()
+		}
+	})
+	return c.repository
+}
+
+// 获取namespace的类型化读取实例，配置了Decryptor时读取会自动解密 ENC(...) 格式的值
+func (c *Client) Config(namespaceName string) *Config {
+	return NewConfig(c.namespaceSource(namespaceName))
+}
+
+// 获取配置仓库中namespace的配置值来源，配置了Decryptor时读取会自动解密
+func (c *Client) namespaceSource(namespaceName string) Source {
+	return NewDecryptingSource(&namespaceSource{repository: c.Repository(), namespaceName: namespaceName}, c.Decryptor)
+}
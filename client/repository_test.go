@@ -0,0 +1,84 @@
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRepository(t *testing.T) {
+	repository := NewRepository()
+	config := repository.Config("application")
+	if _, err := config.GetString("timeout"); err == nil {
+		t.Fatal("GetString should return error before namespace is loaded")
+	}
+
+	event := repository.Set(&Configs{NamespaceName: "application", Configurations: Configurations{"timeout": "100"}, ReleaseKey: "r1"})
+	if event.Added["timeout"] != "100" || event.ReleaseKey != "r1" {
+		t.Fatal(fmt.Sprintf("unexpected change event: %v", event))
+	}
+	if config.GetIntOrDefault("timeout", 0) != 100 {
+		t.Fatal("Config should read latest configs from repository")
+	}
+
+	event = repository.Set(&Configs{NamespaceName: "application", Configurations: Configurations{"timeout": "200"}, ReleaseKey: "r2"})
+	if event.Modified["timeout"].OldValue != "100" {
+		t.Fatal(fmt.Sprintf("unexpected change event: %v", event))
+	}
+	if config.GetIntOrDefault("timeout", 0) != 200 {
+		t.Fatal("Config should read latest configs from repository")
+	}
+
+	repository.Set(&Configs{NamespaceName: "TEAM.test_case_1"})
+	if !reflect.DeepEqual(repository.NamespaceNames(), []string{"TEAM.test_case_1", "application"}) {
+		t.Fatal(fmt.Sprintf("unexpected namespaceNames: %v", repository.NamespaceNames()))
+	}
+	if configs, ok := repository.Get("application"); !ok || configs.ReleaseKey != "r2" {
+		t.Fatal("Get should return latest configs")
+	}
+}
+
+func TestRepositoryFlattenedChangeEvent(t *testing.T) {
+	repository := NewRepository()
+	event := repository.Set(&Configs{NamespaceName: "db.yaml", Configurations: Configurations{CONTENT_KEY: "db:\n  host: 10.0.0.1\n  port: 3306\n"}})
+	if len(event.Added) != 2 || event.Added["db.host"] != "10.0.0.1" {
+		t.Fatal(fmt.Sprintf("first load should add flattened keys: %v", event))
+	}
+
+	event = repository.Set(&Configs{NamespaceName: "db.yaml", Configurations: Configurations{CONTENT_KEY: "db:\n  host: 10.0.0.2\n  port: 3306\n  user: root\n"}})
+	if !event.IsChanged("db.host") || event.Modified["db.host"] != (ValueChange{OldValue: "10.0.0.1", NewValue: "10.0.0.2"}) ||
+		event.Added["db.user"] != "root" || event.IsChanged("db.port") || event.IsChanged(CONTENT_KEY) {
+		t.Fatal(fmt.Sprintf("change event should diff flattened keys: %v", event))
+	}
+
+	//只有格式变化时没有key变更
+	event = repository.Set(&Configs{NamespaceName: "db.yaml", Configurations: Configurations{CONTENT_KEY: "db: {host: 10.0.0.2, port: 3306, user: root}\n"}})
+	if !event.IsEmpty() {
+		t.Fatal(fmt.Sprintf("reformatted content should not change any key: %v", event))
+	}
+}
+
+func TestRepositoryInvalidContent(t *testing.T) {
+	var logs bytes.Buffer
+	client := &Client{Logger: log.New(&logs, "", 0)}
+	client.applyConfigs(&Configs{NamespaceName: "db.yaml", ReleaseKey: "r1", Configurations: Configurations{CONTENT_KEY: "db:\n  host: 10.0.0.1\n"}})
+
+	event := client.applyConfigs(&Configs{NamespaceName: "db.yaml", ReleaseKey: "r2", Configurations: Configurations{CONTENT_KEY: "db: [invalid\n"}})
+	if !event.IsEmpty() {
+		t.Fatal(fmt.Sprintf("invalid content should not emit deletions: %v", event))
+	}
+	if !strings.Contains(logs.String(), "db.yaml") {
+		t.Fatal(fmt.Sprintf("parse error should be logged: %s", logs.String()))
+	}
+	if host, err := client.Config("db.yaml").GetString("db.host"); err != nil || host != "10.0.0.1" {
+		t.Fatal(fmt.Sprintf("previous flattened configs should be kept, host: %s, err: %v", host, err))
+	}
+
+	event = client.applyConfigs(&Configs{NamespaceName: "db.yaml", ReleaseKey: "r3", Configurations: Configurations{CONTENT_KEY: "db:\n  host: 10.0.0.2\n"}})
+	if event.Modified["db.host"].NewValue != "10.0.0.2" {
+		t.Fatal(fmt.Sprintf("valid content should be applied again: %v", event))
+	}
+}
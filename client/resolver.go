@@ -0,0 +1,223 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	PLACEHOLDER_PREFIX            = "${"
+	PLACEHOLDER_SUFFIX            = "}"
+	PLACEHOLDER_DEFAULT_SEPARATOR = ":"
+	//占位符前加上该字符时不做替换，如 \${key} 会原样输出 ${key}
+	PLACEHOLDER_ESCAPE = `\`
+)
+
+var (
+	ErrUnresolvedPlaceholder = errors.New("unresolved placeholder")
+	ErrPlaceholderCycle      = errors.New("placeholder cycle")
+)
+
+// 占位符解析器，将配置值中的 ${key} 和 ${key:default} 替换为对应key的值
+//
+// 可以由多个配置值来源组成，越靠前的来源优先级越高，被引用的key同样按该顺序查找；
+// 每次读取都会基于来源的最新配置重新解析，因此配置变更后读取到的总是最新的解析结果
+type Resolver struct {
+	sources []Source
+}
+
+// 可以返回解析错误的配置值来源，Config读取时会将错误返回给调用方
+type resolvingSource interface {
+	Resolve(key string) (string, error)
+}
+
+// 基于多个配置值来源构建解析器，越靠前的来源优先级越高
+func NewResolver(sources ...Source) *Resolver {
+	return &Resolver{sources: sources}
+}
+
+// 基于客户端配置仓库中的多个namespace构建解析器，越靠前的namespace优先级越高
+func (c *Client) Resolver(namespaceNames ...string) *Resolver {
+	sources := make([]Source, 0, len(namespaceNames))
+	for _, namespaceName := range namespaceNames {
+		sources = append(sources, c.namespaceSource(namespaceName))
+	}
+	return NewResolver(sources...)
+}
+
+// 获取key解析后的值，key不存在时返回ErrKeyNotFound，
+// 引用的key不存在且没有默认值时返回ErrUnresolvedPlaceholder，循环引用时返回ErrPlaceholderCycle
+func (r *Resolver) Resolve(key string) (string, error) {
+	value, ok, err := r.lookupRaw(key)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrKeyNotFound, key)
+	}
+	return r.expand(value, []string{key})
+}
+
+// 查找key解析后的值，解析失败时返回false，需要错误原因时使用 Resolve
+func (r *Resolver) Lookup(key string) (string, bool) {
+	value, err := r.Resolve(key)
+	return value, err == nil
+}
+
+// 替换任意字符串中的占位符
+func (r *Resolver) Expand(value string) (string, error) {
+	return r.expand(value, nil)
+}
+
+// 获取所有来源中的key（已排序）
+func (r *Resolver) Keys() []string {
+	seen := map[string]bool{}
+	keys := make([]string, 0)
+	for _, source := range r.sources {
+		keysSource, ok := source.(KeysSource)
+		if !ok {
+			continue
+		}
+		for _, key := range keysSource.Keys() {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// 解析所有key，返回解析后的配置快照
+func (r *Resolver) ResolveAll() (Configurations, error) {
+	keys := r.Keys()
+	resolved := make(Configurations, len(keys))
+	for _, key := range keys {
+		value, err := r.Resolve(key)
+		if err != nil {
+			return nil, err
+		}
+		resolved[key] = value
+	}
+	return resolved, nil
+}
+
+// 按优先级查找未解析的原始值
+func (r *Resolver) lookupRaw(key string) (string, bool, error) {
+	for _, source := range r.sources {
+		value, ok, err := lookup(source, key)
+		if err != nil {
+			return "", false, err
+		}
+		if ok {
+			return value, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// 替换value中的占位符，path为当前正在解析的key链路，用于检测循环引用
+func (r *Resolver) expand(value string, path []string) (string, error) {
+	var builder strings.Builder
+	for {
+		start := strings.Index(value, PLACEHOLDER_PREFIX)
+		if start < 0 {
+			builder.WriteString(value)
+			return builder.String(), nil
+		}
+		if strings.HasSuffix(value[:start], PLACEHOLDER_ESCAPE) {
+			builder.WriteString(value[:start-len(PLACEHOLDER_ESCAPE)])
+			builder.WriteString(PLACEHOLDER_PREFIX)
+			value = value[start+len(PLACEHOLDER_PREFIX):]
+			continue
+		}
+		end := placeholderEnd(value, start+len(PLACEHOLDER_PREFIX))
+		//没有闭合的占位符按普通文本处理
+		if end < 0 {
+			builder.WriteString(value)
+			return builder.String(), nil
+		}
+		builder.WriteString(value[:start])
+		resolved, err := r.resolvePlaceholder(value[start+len(PLACEHOLDER_PREFIX):end], path)
+		if err != nil {
+			return "", err
+		}
+		builder.WriteString(resolved)
+		value = value[end+len(PLACEHOLDER_SUFFIX):]
+	}
+}
+
+// 解析单个占位符的内容（key或key:default）
+func (r *Resolver) resolvePlaceholder(placeholder string, path []string) (string, error) {
+	key, defaultValue, hasDefault := placeholder, "", false
+	if i := defaultSeparatorIndex(placeholder); i >= 0 {
+		key, defaultValue, hasDefault = placeholder[:i], placeholder[i+len(PLACEHOLDER_DEFAULT_SEPARATOR):], true
+	}
+	//key中也可以包含占位符，如 ${host.${env}}
+	key, err := r.expand(key, path)
+	if err != nil {
+		return "", err
+	}
+
+	//复制一份，避免多个占位符共用底层数组
+	next := make([]string, len(path), len(path)+1)
+	copy(next, path)
+	next = append(next, key)
+	for _, k := range path {
+		if k == key {
+			return "", fmt.Errorf("%w: %s", ErrPlaceholderCycle, strings.Join(next, " -> "))
+		}
+	}
+
+	value, ok, err := r.lookupRaw(key)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		if !hasDefault {
+			if len(path) > 0 {
+				return "", fmt.Errorf("%w: ${%s} referenced by %s", ErrUnresolvedPlaceholder, key, path[len(path)-1])
+			}
+			return "", fmt.Errorf("%w: ${%s}", ErrUnresolvedPlaceholder, key)
+		}
+		return r.expand(defaultValue, path)
+	}
+	return r.expand(value, next)
+}
+
+// 查找不在嵌套占位符内的默认值分隔符位置，找不到时返回-1
+func defaultSeparatorIndex(placeholder string) int {
+	depth := 0
+	for i := 0; i < len(placeholder); i++ {
+		switch {
+		case strings.HasPrefix(placeholder[i:], PLACEHOLDER_PREFIX):
+			depth++
+			i += len(PLACEHOLDER_PREFIX) - 1
+		case strings.HasPrefix(placeholder[i:], PLACEHOLDER_SUFFIX):
+			depth--
+		case depth == 0 && strings.HasPrefix(placeholder[i:], PLACEHOLDER_DEFAULT_SEPARATOR):
+			return i
+		}
+	}
+	return -1
+}
+
+// 查找与占位符开始位置匹配的结束位置，支持嵌套（如 ${a:${b}}），找不到时返回-1
+func placeholderEnd(value string, from int) int {
+	depth := 1
+	for i := from; i < len(value); i++ {
+		if strings.HasPrefix(value[i:], PLACEHOLDER_PREFIX) {
+			depth++
+			i += len(PLACEHOLDER_PREFIX) - 1
+		} else if strings.HasPrefix(value[i:], PLACEHOLDER_SUFFIX) {
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
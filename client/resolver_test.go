@@ -0,0 +1,112 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestResolver(t *testing.T) {
+	resolver := NewResolver(
+		Configurations{
+			"db.url":     "jdbc://${db.host}:${db.port}/${db.name:test}",
+			"db.host":    "${host.${env}}",
+			"env":        "prod",
+			"escaped":    `\${db.host}`,
+			"unclosed":   "${db.host",
+			"nested":     "${missing:${db.port}}",
+			"empty":      "${missing:}",
+			"overridden": "application",
+		},
+		Configurations{
+			"host.prod":  "10.0.0.1",
+			"db.port":    "3306",
+			"overridden": "public",
+		},
+	)
+
+	cases := map[string]string{
+		"db.url":     "jdbc://10.0.0.1:3306/test",
+		"escaped":    "${db.host}",
+		"unclosed":   "${db.host",
+		"nested":     "3306",
+		"empty":      "",
+		"overridden": "application",
+	}
+	for key, expect := range cases {
+		value, err := resolver.Resolve(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value != expect {
+			t.Fatal(fmt.Sprintf("unexpected value of %s: %s", key, value))
+		}
+	}
+	if value, err := resolver.Expand("${env}-${db.port}"); err != nil || value != "prod-3306" {
+		t.Fatal(fmt.Sprintf("Expand returns wrong result: %s, err: %v", value, err))
+	}
+	if _, err := resolver.Resolve("not-exists"); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatal(fmt.Sprintf("Resolve should return ErrKeyNotFound, err: %v", err))
+	}
+	resolved, err := resolver.ResolveAll()
+	if err != nil || len(resolved) != 10 || resolved["db.host"] != "10.0.0.1" {
+		t.Fatal(fmt.Sprintf("ResolveAll returns wrong result: %v, err: %v", resolved, err))
+	}
+}
+
+func TestResolverError(t *testing.T) {
+	resolver := NewResolver(Configurations{
+		"a":          "${b}",
+		"b":          "${c}",
+		"c":          "${a}",
+		"self":       "${self}",
+		"unresolved": "${missing}",
+		"port":       "${missing:abc}",
+	})
+	for _, key := range []string{"a", "self"} {
+		if _, err := resolver.Resolve(key); !errors.Is(err, ErrPlaceholderCycle) {
+			t.Fatal(fmt.Sprintf("Resolve should return ErrPlaceholderCycle, err: %v", err))
+		}
+	}
+	if _, err := resolver.Resolve("unresolved"); !errors.Is(err, ErrUnresolvedPlaceholder) {
+		t.Fatal(fmt.Sprintf("Resolve should return ErrUnresolvedPlaceholder, err: %v", err))
+	}
+	if _, err := resolver.ResolveAll(); err == nil {
+		t.Fatal("ResolveAll should return error")
+	}
+
+	config := NewConfig(resolver)
+	if _, err := config.GetString("unresolved"); !errors.Is(err, ErrUnresolvedPlaceholder) {
+		t.Fatal(fmt.Sprintf("Config should return resolve error, err: %v", err))
+	}
+	var parseErr *ParseError
+	if _, err := config.GetInt("port"); !errors.As(err, &parseErr) {
+		t.Fatal(fmt.Sprintf("Config should return ParseError, err: %v", err))
+	}
+	if _, err := config.GetString("not-exists"); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatal(fmt.Sprintf("Config should return ErrKeyNotFound, err: %v", err))
+	}
+
+	var v struct {
+		A string `apollo:"a"`
+	}
+	if err := config.Unmarshal(&v); !errors.Is(err, ErrPlaceholderCycle) {
+		t.Fatal(fmt.Sprintf("Unmarshal should return resolve error, err: %v", err))
+	}
+}
+
+func TestClientResolver(t *testing.T) {
+	client := &Client{}
+	client.Repository().Set(&Configs{NamespaceName: "application", Configurations: Configurations{"url": "http://${host}:${port:80}"}})
+	client.Repository().Set(&Configs{NamespaceName: "TEAM.public", Configurations: Configurations{"host": "a.example.com"}})
+
+	config := NewConfig(client.Resolver("application", "TEAM.public"))
+	if url, err := config.GetString("url"); err != nil || url != "http://a.example.com:80" {
+		t.Fatal(fmt.Sprintf("unexpected url: %s, err: %v", url, err))
+	}
+
+	client.Repository().Set(&Configs{NamespaceName: "TEAM.public", Configurations: Configurations{"host": "b.example.com", "port": "8080"}})
+	if url, err := config.GetString("url"); err != nil || url != "http://b.example.com:8080" {
+		t.Fatal(fmt.Sprintf("Resolver should re-resolve after change: %s, err: %v", url, err))
+	}
+}
@@ -0,0 +1,115 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"github.com/flylan/apollo-config-lib/request"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+const (
+	DEFAULT_RETRY_MAX_ATTEMPTS = 3
+	DEFAULT_RETRY_BASE_DELAY   = 1 * time.Second
+	DEFAULT_RETRY_MAX_DELAY    = 2 * time.Minute
+	DEFAULT_RETRY_JITTER       = 0.2
+)
+
+// 重试策略，请求间隔按指数退避增长
+type RetryPolicy struct {
+	//最大请求次数（包含第一次请求），小于等于1时不重试
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	//随机抖动比例（0~1），实际间隔在 [delay*(1-Jitter), delay] 之间
+	Jitter               float64
+	RetryableStatusCodes []int
+	RetryNetworkErrors   bool
+}
+
+// 默认重试策略：最多请求3次，429和5xx状态码以及网络错误会重试
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: DEFAULT_RETRY_MAX_ATTEMPTS,
+		BaseDelay:   DEFAULT_RETRY_BASE_DELAY,
+		MaxDelay:    DEFAULT_RETRY_MAX_DELAY,
+		Jitter:      DEFAULT_RETRY_JITTER,
+		RetryableStatusCodes: []int{
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+		RetryNetworkErrors: true,
+	}
+}
+
+// 计算第attempt次失败后的等待时间（attempt从1开始），未配置BaseDelay时使用 DEFAULT_RETRY_BASE_DELAY
+func (p *RetryPolicy) Backoff(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	delay := p.BaseDelay
+	if delay <= 0 {
+		delay = DEFAULT_RETRY_BASE_DELAY
+	}
+	for i := 1; i < attempt && (p.MaxDelay <= 0 || delay < p.MaxDelay); i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if p.Jitter > 0 && delay > 0 {
+		jitter := p.Jitter
+		if jitter > 1 {
+			jitter = 1
+		}
+		delay -= time.Duration(rand.Float64() * jitter * float64(delay))
+	}
+	return delay
+}
+
+// 判断请求结果是否需要重试，证书校验失败等非网络错误不会重试
+func (p *RetryPolicy) IsRetryable(info *request.Info, err error) bool {
+	if err != nil {
+		return p.RetryNetworkErrors && isNetworkError(err)
+	}
+	for _, statusCode := range p.RetryableStatusCodes {
+		if info.StatusCode == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// 判断是否为网络传输错误（连接失败、超时、连接被重置或提前关闭）
+func isNetworkError(err error) bool {
+	//url.Error本身实现了net.Error，需要判断其包装的错误
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// 等待一段时间，ctx取消时提前返回
+func sleepWithContext(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
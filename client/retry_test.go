@@ -0,0 +1,122 @@
+package client
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"github.com/flylan/apollo-config-lib/apollotest"
+	"github.com/flylan/apollo-config-lib/request"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := &RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	expects := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second}
+	for i, expect := range expects {
+		if delay := policy.Backoff(i + 1); delay != expect {
+			t.Fatal(fmt.Sprintf("Backoff(%d) error, expect: %v, but: %v", i+1, expect, delay))
+		}
+	}
+	if delay := policy.Backoff(1000); delay != time.Second {
+		t.Fatal(fmt.Sprintf("Backoff should not exceed MaxDelay, but: %v", delay))
+	}
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if delay := policy.Backoff(3); delay < 200*time.Millisecond || delay > 400*time.Millisecond {
+			t.Fatal(fmt.Sprintf("Backoff with jitter out of range: %v", delay))
+		}
+	}
+
+	policy = &RetryPolicy{MaxAttempts: 3}
+	if delay := policy.Backoff(1); delay != DEFAULT_RETRY_BASE_DELAY {
+		t.Fatal(fmt.Sprintf("Backoff without BaseDelay should use DEFAULT_RETRY_BASE_DELAY, but: %v", delay))
+	}
+}
+
+func TestRetryPolicyIsRetryable(t *testing.T) {
+	policy := DefaultRetryPolicy()
+	if !policy.IsRetryable(&request.Info{StatusCode: http.StatusServiceUnavailable}, nil) ||
+		!policy.IsRetryable(&request.Info{StatusCode: http.StatusTooManyRequests}, nil) {
+		t.Fatal("5xx and 429 should be retryable")
+	}
+	if policy.IsRetryable(&request.Info{StatusCode: http.StatusNotFound}, nil) ||
+		policy.IsRetryable(&request.Info{StatusCode: http.StatusNotModified}, nil) {
+		t.Fatal("404 and 304 should not be retryable")
+	}
+	networkErr := &url.Error{Op: "Get", URL: "http://127.0.0.1", Err: &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}}
+	if !policy.IsRetryable(&request.Info{}, networkErr) {
+		t.Fatal("network error should be retryable")
+	}
+	resetErr := &url.Error{Op: "Get", URL: "http://127.0.0.1", Err: &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}}
+	if !policy.IsRetryable(&request.Info{}, resetErr) {
+		t.Fatal("connection reset should be retryable")
+	}
+	if !policy.IsRetryable(&request.Info{}, &url.Error{Op: "Get", URL: "http://127.0.0.1", Err: io.EOF}) {
+		t.Fatal("connection closed early should be retryable")
+	}
+	if policy.IsRetryable(&request.Info{}, errors.New("RequestUrl is empty")) {
+		t.Fatal("non network error should not be retryable")
+	}
+	certErr := &url.Error{Op: "Get", URL: "https://127.0.0.1", Err: x509.UnknownAuthorityError{}}
+	if policy.IsRetryable(&request.Info{}, certErr) {
+		t.Fatal("certificate error should not be retryable")
+	}
+	policy.RetryNetworkErrors = false
+	if policy.IsRetryable(&request.Info{}, networkErr) {
+		t.Fatal("network error should not be retryable when RetryNetworkErrors is false")
+	}
+}
+
+func TestSendGetRequestRetry(t *testing.T) {
+	var requests int32
+	fake := apollotest.NewServer()
+	defer fake.Close()
+	fake.Publish(testAppId, DEFAULT_CLUSTER_NAME, "application", Configurations{"timeout": "100"})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) <= 2 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		fake.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, testAppId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//未配置重试策略时不重试
+	if _, _, err = client.Configs("application").Get(); err == nil {
+		t.Fatal("Get should return error without retry policy")
+	}
+
+	atomic.StoreInt32(&requests, 0)
+	client.RetryPolicy = DefaultRetryPolicy()
+	client.RetryPolicy.BaseDelay = time.Millisecond
+	configs, _, err := client.Configs("application").Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if configs.Configurations["timeout"] != "100" || atomic.LoadInt32(&requests) != 3 {
+		t.Fatal(fmt.Sprintf("Get should succeed after retry, requests: %d", requests))
+	}
+
+	//超过最大请求次数后返回最后一次的结果
+	atomic.StoreInt32(&requests, -10)
+	if _, info, err := client.Configs("application").Get(); err == nil || info.StatusCode != http.StatusTooManyRequests {
+		t.Fatal("Get should return error after MaxAttempts")
+	}
+	if atomic.LoadInt32(&requests) != -7 {
+		t.Fatal(fmt.Sprintf("Get should send MaxAttempts requests, requests: %d", requests))
+	}
+}
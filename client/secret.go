@@ -0,0 +1,95 @@
+package client
+
+import (
+	"context"
+	"github.com/flylan/apollo-config-lib/request"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// 有序的访问秘钥列表，用于秘钥轮换，可以在运行时更新
+type secretList struct {
+	mu      sync.RWMutex
+	secrets []string
+	//最近一次认证成功的秘钥，之后优先使用
+	preferred string
+}
+
+// 运行时更新访问秘钥列表（按优先级排序），轮换期间可以同时配置新旧秘钥，
+// 请求返回401时会依次使用下一个秘钥重试；传入空列表时恢复使用Secret
+func (c *Client) SetSecrets(secrets ...string) {
+	c.secrets.mu.Lock()
+	defer c.secrets.mu.Unlock()
+	c.secrets.secrets = append([]string(nil), secrets...)
+	c.secrets.preferred = ""
+}
+
+// 获取访问秘钥列表，没有通过SetSecrets（或WithSecrets）设置时返回Secret
+func (c *Client) Secrets() []string {
+	c.secrets.mu.RLock()
+	defer c.secrets.mu.RUnlock()
+	if len(c.secrets.secrets) > 0 {
+		return append([]string(nil), c.secrets.secrets...)
+	}
+	if c.Secret != "" {
+		return []string{c.Secret}
+	}
+	return nil
+}
+
+// 获取本次请求依次尝试的秘钥，最近一次认证成功的秘钥排在最前面，没有秘钥时返回一个空字符串（不签名）
+func (c *Client) orderedSecrets() []string {
+	secrets := c.Secrets()
+	if len(secrets) == 0 {
+		return []string{""}
+	}
+	c.secrets.mu.RLock()
+	preferred := c.secrets.preferred
+	c.secrets.mu.RUnlock()
+	for i, secret := range secrets {
+		if i > 0 && secret == preferred {
+			copy(secrets[1:i+1], secrets[:i])
+			secrets[0] = secret
+			break
+		}
+	}
+	return secrets
+}
+
+// 记录认证成功的秘钥
+func (c *Client) preferSecret(secret string) {
+	c.secrets.mu.Lock()
+	defer c.secrets.mu.Unlock()
+	c.secrets.preferred = secret
+}
+
+// 获取使用指定秘钥时的签名器，配置了Signer时在Apollo签名之后执行，未配置时返回nil（使用默认的Apollo签名）
+func (c *Client) signer(secret string) request.Signer {
+	if c.Signer == nil {
+		return nil
+	}
+	return request.Signers{request.NewHmacSigner(c.AppId, secret), c.Signer}
+}
+
+// 向同一个地址发送请求，返回401时使用下一个秘钥重试
+func (c *Client) sendGetRequestWithSecrets(ctx context.Context, requestUrl string, timeout time.Duration, info *request.Info) (*request.Info, error) {
+	secrets := c.orderedSecrets()
+	sender := request.NewSender(c.HttpClient)
+	var err error
+	for i, secret := range secrets {
+		*info = request.Info{}
+		sender.Signer = c.signer(secret)
+		info, err = sender.SendGetRequest(ctx, requestUrl, c.AppId, secret, timeout, info)
+		if err != nil || info.StatusCode != http.StatusUnauthorized {
+			if err == nil && len(secrets) > 1 {
+				c.preferSecret(secret)
+			}
+			return info, err
+		}
+		if i < len(secrets)-1 {
+			c.logf("Apollo request %s unauthorized with secret #%d, retrying with next secret", requestUrl, i+1)
+		}
+	}
+	return info, err
+}
@@ -0,0 +1,94 @@
+package client
+
+import (
+	"fmt"
+	"github.com/flylan/apollo-config-lib/apollotest"
+	"github.com/flylan/apollo-config-lib/request"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestSecrets(t *testing.T) {
+	client := &Client{Secret: "old"}
+	if !reflect.DeepEqual(client.Secrets(), []string{"old"}) {
+		t.Fatal(fmt.Sprintf("Secrets should fall back to Secret: %v", client.Secrets()))
+	}
+	client.SetSecrets("a", "b", "c")
+	client.preferSecret("c")
+	if !reflect.DeepEqual(client.orderedSecrets(), []string{"c", "a", "b"}) {
+		t.Fatal(fmt.Sprintf("preferred secret should be tried first: %v", client.orderedSecrets()))
+	}
+	if !reflect.DeepEqual(client.Secrets(), []string{"a", "b", "c"}) {
+		t.Fatal(fmt.Sprintf("orderedSecrets should not modify secrets: %v", client.Secrets()))
+	}
+	client.SetSecrets()
+	client.Secret = ""
+	if !reflect.DeepEqual(client.orderedSecrets(), []string{""}) {
+		t.Fatal(fmt.Sprintf("request without secret should not be signed: %v", client.orderedSecrets()))
+	}
+}
+
+func TestSecretRotation(t *testing.T) {
+	server := apollotest.NewServer()
+	defer server.Close()
+	server.Publish(testAppId, DEFAULT_CLUSTER_NAME, "application", Configurations{"timeout": "100"})
+	server.SetSecret(testAppId, "new-secret")
+
+	client, err := NewClient(server.URL, testAppId, WithSecrets("old-secret", "new-secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	configs, info, err := client.Configs("application").Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.StatusCode != http.StatusOK || configs.Configurations["timeout"] != "100" {
+		t.Fatal(fmt.Sprintf("request should succeed with next secret: %d", info.StatusCode))
+	}
+	if server.RequestCount("/configs") != 2 {
+		t.Fatal(fmt.Sprintf("unexpected request count: %d", server.RequestCount("/configs")))
+	}
+
+	//认证成功的秘钥之后会优先使用
+	if _, _, err = client.Configs("application").Get(); err != nil {
+		t.Fatal(err)
+	}
+	if server.RequestCount("/configs") != 3 {
+		t.Fatal(fmt.Sprintf("working secret should be tried first, request count: %d", server.RequestCount("/configs")))
+	}
+
+	//运行时更新秘钥
+	server.SetSecret(testAppId, "newer-secret")
+	if _, _, err = client.Configs("application").Get(); err == nil {
+		t.Fatal("request should fail when every secret is rejected")
+	}
+	client.SetSecrets("newer-secret")
+	if _, _, err = client.Configs("application").Get(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWithSigner(t *testing.T) {
+	server := apollotest.NewServer()
+	defer server.Close()
+	server.Publish(testAppId, DEFAULT_CLUSTER_NAME, "application", Configurations{"timeout": "100"})
+	server.SetSecret(testAppId, testSecret)
+
+	var requestIds []string
+	transport := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		requestIds = append(requestIds, r.Header.Get("X-Request-Id"))
+		return http.DefaultTransport.RoundTrip(r)
+	})
+	signer := request.HeaderSigner(map[string]string{"X-Request-Id": "gateway"})
+	client, err := NewClient(server.URL, testAppId, WithSecret(testSecret), WithSigner(signer), WithTransport(transport))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = client.Configs("application").Get(); err != nil {
+		t.Fatal(fmt.Sprintf("Apollo signature should be kept with custom signer, err: %v", err))
+	}
+	if !reflect.DeepEqual(requestIds, []string{"gateway"}) {
+		t.Fatal(fmt.Sprintf("custom signer should be applied: %v", requestIds))
+	}
+}
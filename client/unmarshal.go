@@ -0,0 +1,342 @@
+package client
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	TAG_APOLLO   = "apollo"
+	TAG_DEFAULT  = "default"
+	TAG_REQUIRED = "required"
+	TAG_SEP      = "sep"
+
+	KEY_SEPARATOR           = "."
+	DEFAULT_SLICE_SEPARATOR = ","
+)
+
+var (
+	ErrRequired = errors.New("required key is missing")
+
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// 可以枚举所有key的配置值来源，绑定map类型字段时使用
+type KeysSource interface {
+	Source
+	Keys() []string
+}
+
+// 单个字段绑定失败的原因
+type FieldError struct {
+	Field string
+	Key   string
+	Err   error
+}
+
+// 结构体绑定失败，包含所有绑定失败的字段
+type UnmarshalError struct {
+	Errors []*FieldError
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("field %s (key %s): %v", e.Field, e.Key, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+func (e *UnmarshalError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		messages = append(messages, err.Error())
+	}
+	return "Unable to unmarshal configurations: " + strings.Join(messages, "; ")
+}
+
+// 判断是否包含指定的错误，支持 errors.Is(err, ErrRequired)
+func (e *UnmarshalError) Is(target error) bool {
+	for _, err := range e.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+type decoder struct {
+	source Source
+	errors []*FieldError
+}
+
+// 将namespace配置绑定到结构体，v必须是结构体指针
+//
+// 字段通过 apollo:"key" 标签指定对应的key，未设置标签的字段会被忽略（匿名嵌入的结构体除外）；
+// 结构体类型的字段以 key. 作为前缀绑定其内部字段；切片按 sep 标签指定的分隔符（默认逗号）拆分；
+// map类型的字段优先按JSON解析同名key，否则收集所有 key. 前缀的配置；
+// key不存在时使用 default 标签的值，设置了 required:"true" 且没有默认值时返回 ErrRequired
+func Unmarshal(configs *Configs, v interface{}) error {
+	if configs == nil {
+		return errors.New("Configs is nil")
+	}
+	return unmarshal(configs.Configurations, v)
+}
+
+// 将配置绑定到结构体，规则同 Unmarshal
+func (c *Config) Unmarshal(v interface{}) error {
+	return unmarshal(c.source, v)
+}
+
+func unmarshal(source Source, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("Unmarshal target must be a non-nil pointer to struct")
+	}
+	d := &decoder{source: source}
+	d.decodeStruct(rv.Elem(), "", rv.Elem().Type().Name())
+	if len(d.errors) > 0 {
+		return &UnmarshalError{Errors: d.errors}
+	}
+	return nil
+}
+
+// 绑定结构体的所有字段
+func (d *decoder) decodeStruct(v reflect.Value, prefix, path string) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+		tag, hasTag := field.Tag.Lookup(TAG_APOLLO)
+		if tag == "-" {
+			continue
+		}
+		fieldPath := path + KEY_SEPARATOR + field.Name
+
+		//未设置标签的匿名嵌入结构体，字段直接使用当前前缀
+		if !hasTag {
+			if field.Anonymous && isNestedStruct(field.Type) {
+				d.decodeNestedStruct(v.Field(i), prefix, prefix, fieldPath)
+			}
+			continue
+		}
+		d.decodeField(field, v.Field(i), prefix+tag, fieldPath)
+	}
+}
+
+// 绑定嵌套结构体，未导出的匿名结构体指针为nil时无法分配内存，记录为字段错误
+func (d *decoder) decodeNestedStruct(v reflect.Value, key, prefix, path string) {
+	nested, err := allocate(v)
+	if err != nil {
+		d.fail(path, key, err)
+		return
+	}
+	d.decodeStruct(nested, prefix, path)
+}
+
+// 绑定单个字段
+func (d *decoder) decodeField(field reflect.StructField, v reflect.Value, key, path string) {
+	if isNestedStruct(field.Type) {
+		d.decodeNestedStruct(v, key, key+KEY_SEPARATOR, path)
+		return
+	}
+	//带标签的未导出匿名字段无法赋值
+	if !v.CanSet() {
+		d.fail(path, key, fmt.Errorf("Unable to set unexported embedded field %s", field.Name))
+		return
+	}
+
+	value, ok, err := lookup(d.source, key)
+	if err != nil {
+		d.fail(path, key, err)
+		return
+	}
+	if !ok && indirectType(field.Type).Kind() == reflect.Map {
+		if ok = d.decodePrefixMap(v, key, path); ok {
+			return
+		}
+	}
+	if !ok {
+		value, ok = field.Tag.Lookup(TAG_DEFAULT)
+	}
+	if !ok {
+		if field.Tag.Get(TAG_REQUIRED) == "true" {
+			d.fail(path, key, ErrRequired)
+		}
+		return
+	}
+
+	sep := field.Tag.Get(TAG_SEP)
+	if sep == "" {
+		sep = DEFAULT_SLICE_SEPARATOR
+	}
+	if err = setValue(v, value, sep); err != nil {
+		d.fail(path, key, err)
+	}
+}
+
+// 收集所有 key. 前缀的配置绑定到map字段，没有匹配的key时返回false
+func (d *decoder) decodePrefixMap(v reflect.Value, key, path string) bool {
+	keysSource, ok := d.source.(KeysSource)
+	if !ok {
+		return false
+	}
+	prefix := key + KEY_SEPARATOR
+	var m reflect.Value
+	for _, k := range keysSource.Keys() {
+		if !strings.HasPrefix(k, prefix) {
+			continue
+		}
+		value, _, err := lookup(d.source, k)
+		if err != nil {
+			d.fail(path, k, err)
+			continue
+		}
+		if !m.IsValid() {
+			m = reflect.MakeMap(indirectType(v.Type()))
+		}
+		if err := setMapEntry(m, strings.TrimPrefix(k, prefix), value); err != nil {
+			d.fail(path, k, err)
+		}
+	}
+	if !m.IsValid() {
+		return false
+	}
+	target, err := allocate(v)
+	if err != nil {
+		d.fail(path, key, err)
+		return true
+	}
+	target.Set(m)
+	return true
+}
+
+func (d *decoder) fail(path, key string, err error) {
+	d.errors = append(d.errors, &FieldError{Field: path, Key: key, Err: err})
+}
+
+// 将字符串转换为字段类型并赋值
+func setValue(v reflect.Value, value, sep string) error {
+	if v.Kind() == reflect.Ptr {
+		elem := reflect.New(v.Type().Elem())
+		if err := setValue(elem.Elem(), value, sep); err != nil {
+			return err
+		}
+		v.Set(elem)
+		return nil
+	}
+	if reflect.PtrTo(v.Type()).Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+	}
+	if v.Type() == durationType {
+		d, err := parseDuration(strings.TrimSpace(value))
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d.(time.Duration)))
+		return nil
+	}
+
+	trimmed := strings.TrimSpace(value)
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(trimmed)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(trimmed, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(trimmed, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(trimmed, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		items := splitString(value, sep)
+		slice := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := setValue(slice.Index(i), item, sep); err != nil {
+				return fmt.Errorf("index %d: %w", i, err)
+			}
+		}
+		v.Set(slice)
+	case reflect.Map:
+		var entries map[string]string
+		if err := json.Unmarshal([]byte(value), &entries); err != nil {
+			return err
+		}
+		m := reflect.MakeMapWithSize(v.Type(), len(entries))
+		for key, entry := range entries {
+			if err := setMapEntry(m, key, entry); err != nil {
+				return err
+			}
+		}
+		v.Set(m)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// 设置map的一项，map的key必须是字符串类型
+func setMapEntry(m reflect.Value, key, value string) error {
+	if m.Type().Key().Kind() != reflect.String {
+		return fmt.Errorf("unsupported map key type %s", m.Type().Key())
+	}
+	elem := reflect.New(m.Type().Elem()).Elem()
+	if err := setValue(elem, value, DEFAULT_SLICE_SEPARATOR); err != nil {
+		return fmt.Errorf("map key %s: %w", key, err)
+	}
+	m.SetMapIndex(reflect.ValueOf(key).Convert(m.Type().Key()), elem)
+	return nil
+}
+
+// 判断是否为需要展开绑定的结构体（实现了TextUnmarshaler的结构体按单个值处理）
+func isNestedStruct(t reflect.Type) bool {
+	t = indirectType(t)
+	return t.Kind() == reflect.Struct && !reflect.PtrTo(t).Implements(textUnmarshalerType)
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Ptr {
+		return t.Elem()
+	}
+	return t
+}
+
+// 指针字段为空时分配内存，返回指向的值，与 encoding/json 一致，未导出的匿名结构体指针为nil时返回错误
+func allocate(v reflect.Value) (reflect.Value, error) {
+	if v.Kind() != reflect.Ptr {
+		return v, nil
+	}
+	if v.IsNil() {
+		if !v.CanSet() {
+			return v, fmt.Errorf("Unable to set embedded pointer to unexported struct %s", v.Type().Elem())
+		}
+		v.Set(reflect.New(v.Type().Elem()))
+	}
+	return v.Elem(), nil
+}
@@ -0,0 +1,163 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testDatabaseConfig struct {
+	Host    string        `apollo:"host" default:"localhost"`
+	Port    int           `apollo:"port" required:"true"`
+	Timeout time.Duration `apollo:"timeout" default:"3s"`
+}
+
+type testBaseConfig struct {
+	Name string `apollo:"name"`
+}
+
+type testAppConfig struct {
+	testBaseConfig
+	Debug    bool                `apollo:"debug"`
+	Ratio    float64             `apollo:"ratio"`
+	MaxConns *uint16             `apollo:"maxConns"`
+	Hosts    []string            `apollo:"hosts"`
+	Ports    []int               `apollo:"ports" sep:";"`
+	Weights  map[string]int      `apollo:"weights"`
+	Labels   map[string]string   `apollo:"labels"`
+	Database testDatabaseConfig  `apollo:"db"`
+	Cache    *testDatabaseConfig `apollo:"cache"`
+	Ignored  string
+	Skipped  string `apollo:"-"`
+	private  string `apollo:"private"`
+}
+
+func TestUnmarshal(t *testing.T) {
+	configs := &Configs{Configurations: Configurations{
+		"name":       "demo",
+		"debug":      "true",
+		"ratio":      "0.5",
+		"maxConns":   "100",
+		"hosts":      "a.example.com, b.example.com",
+		"ports":      "80;443",
+		"weights":    `{"a":"1","b":"2"}`,
+		"labels.env": "prod",
+		"labels.idc": "sh",
+		"db.host":    "10.0.0.1",
+		"db.port":    "3306",
+		"db.timeout": "500",
+		"cache.port": "6379",
+		"Ignored":    "value",
+		"-":          "value",
+		"private":    "value",
+	}}
+
+	var config testAppConfig
+	if err := Unmarshal(configs, &config); err != nil {
+		t.Fatal(err)
+	}
+	if config.Name != "demo" || !config.Debug || config.Ratio != 0.5 || config.MaxConns == nil || *config.MaxConns != 100 {
+		t.Fatal(fmt.Sprintf("unexpected scalar fields: %+v", config))
+	}
+	if !reflect.DeepEqual(config.Hosts, []string{"a.example.com", "b.example.com"}) || !reflect.DeepEqual(config.Ports, []int{80, 443}) {
+		t.Fatal(fmt.Sprintf("unexpected slice fields: %v %v", config.Hosts, config.Ports))
+	}
+	if !reflect.DeepEqual(config.Weights, map[string]int{"a": 1, "b": 2}) || !reflect.DeepEqual(config.Labels, map[string]string{"env": "prod", "idc": "sh"}) {
+		t.Fatal(fmt.Sprintf("unexpected map fields: %v %v", config.Weights, config.Labels))
+	}
+	if config.Database != (testDatabaseConfig{Host: "10.0.0.1", Port: 3306, Timeout: 500 * time.Millisecond}) {
+		t.Fatal(fmt.Sprintf("unexpected nested struct: %+v", config.Database))
+	}
+	if config.Cache == nil || *config.Cache != (testDatabaseConfig{Host: "localhost", Port: 6379, Timeout: 3 * time.Second}) {
+		t.Fatal(fmt.Sprintf("unexpected nested struct pointer: %+v", config.Cache))
+	}
+	if config.Ignored != "" || config.Skipped != "" || config.private != "" {
+		t.Fatal("fields without apollo tag should be ignored")
+	}
+}
+
+func TestUnmarshalError(t *testing.T) {
+	var config testAppConfig
+	if err := Unmarshal(&Configs{}, config); err == nil {
+		t.Fatal("Unmarshal should return error when target is not a pointer")
+	}
+	if err := Unmarshal(nil, &config); err == nil {
+		t.Fatal("Unmarshal should return error when configs is nil")
+	}
+
+	err := Unmarshal(&Configs{Configurations: Configurations{
+		"debug":      "yes",
+		"cache.port": "6379",
+	}}, &config)
+	var unmarshalErr *UnmarshalError
+	if !errors.As(err, &unmarshalErr) || len(unmarshalErr.Errors) != 2 {
+		t.Fatal(fmt.Sprintf("Unmarshal should report every invalid field, err: %v", err))
+	}
+	if unmarshalErr.Errors[0].Key != "debug" || unmarshalErr.Errors[1].Key != "db.port" {
+		t.Fatal(fmt.Sprintf("unexpected field errors: %v", err))
+	}
+	if !errors.Is(err, ErrRequired) {
+		t.Fatal(fmt.Sprintf("missing required key should return ErrRequired, err: %v", err))
+	}
+}
+
+func TestConfigUnmarshal(t *testing.T) {
+	repository := NewRepository()
+	repository.Set(&Configs{NamespaceName: "application", Configurations: Configurations{
+		"db.port":      "3306",
+		"weights.a":    "1",
+		"labels.env":   "test",
+		"hosts":        "",
+		"db.timeout":   "1m",
+		"cache.port":   "1",
+		"cache.host":   "cache",
+		"maxConns":     "70000",
+		"cache.ignore": "1",
+	}})
+
+	var config testAppConfig
+	err := repository.Config("application").Unmarshal(&config)
+	var unmarshalErr *UnmarshalError
+	if !errors.As(err, &unmarshalErr) || len(unmarshalErr.Errors) != 1 || unmarshalErr.Errors[0].Key != "maxConns" {
+		t.Fatal(fmt.Sprintf("value out of range should return error, err: %v", err))
+	}
+	if !reflect.DeepEqual(config.Weights, map[string]int{"a": 1}) || len(config.Hosts) != 0 || config.Database.Timeout != time.Minute {
+		t.Fatal(fmt.Sprintf("unexpected config: %+v", config))
+	}
+	if config.Cache.Host != "cache" {
+		t.Fatal(fmt.Sprintf("unexpected nested struct pointer: %+v", config.Cache))
+	}
+}
+
+type testEmbedded struct {
+	A string `apollo:"a"`
+}
+
+type testEmbeddedPointer struct {
+	*testEmbedded
+	B string `apollo:"b"`
+}
+
+func TestUnmarshalUnexportedEmbeddedPointer(t *testing.T) {
+	configs := &Configs{Configurations: Configurations{"a": "1", "b": "2"}}
+
+	var nilPointer testEmbeddedPointer
+	err := Unmarshal(configs, &nilPointer)
+	var unmarshalErr *UnmarshalError
+	if !errors.As(err, &unmarshalErr) || len(unmarshalErr.Errors) != 1 || unmarshalErr.Errors[0].Field != "testEmbeddedPointer.testEmbedded" {
+		t.Fatal(fmt.Sprintf("nil unexported embedded pointer should return field error, err: %v", err))
+	}
+	if nilPointer.B != "2" || nilPointer.testEmbedded != nil {
+		t.Fatal(fmt.Sprintf("other fields should still be bound: %+v", nilPointer))
+	}
+
+	allocated := testEmbeddedPointer{testEmbedded: &testEmbedded{}}
+	if err = Unmarshal(configs, &allocated); err != nil {
+		t.Fatal(err)
+	}
+	if allocated.A != "1" || allocated.B != "2" {
+		t.Fatal(fmt.Sprintf("non-nil unexported embedded pointer should be bound: %+v", allocated))
+	}
+}
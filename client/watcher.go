@@ -0,0 +1,227 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+)
+
+const DEFAULT_WATCH_RETRY_INTERVAL = 1 * time.Second
+
+type Watcher struct {
+	Client        *Client
+	RetryInterval time.Duration
+
+	mu               sync.RWMutex
+	notificationsMap map[string]int64
+	releaseKeys      map[string]string
+	updateHandlers   []func(configs *Configs)
+	errorHandlers    []func(err error)
+	cancel           context.CancelFunc
+	done             chan struct{}
+}
+
+// 构建一个长轮询监听配置变更的实例
+func (c *Client) Watcher(namespaceNames ...string) *Watcher {
+	nm := make(map[string]int64, len(namespaceNames))
+	for _, namespaceName := range namespaceNames {
+		nm[namespaceName] = DEFAULT_NOTIFICATION_ID
+	}
+	return &Watcher{
+		Client:           c,
+		RetryInterval:    DEFAULT_WATCH_RETRY_INTERVAL,
+		notificationsMap: nm,
+		releaseKeys:      map[string]string{},
+	}
+}
+
+// 注册配置更新回调，每次重新拉取到namespace的配置后触发
+func (w *Watcher) OnUpdate(handler func(configs *Configs)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.updateHandlers = append(w.updateHandlers, handler)
+}
+
+// 注册错误回调，长轮询或拉取配置失败时触发
+func (w *Watcher) OnError(handler func(err error)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.errorHandlers = append(w.errorHandlers, handler)
+}
+
+// 获取namespace当前的notificationId
+func (w *Watcher) NotificationId(namespaceName string) int64 {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	notificationId, ok := w.notificationsMap[namespaceName]
+	if !ok {
+		return DEFAULT_NOTIFICATION_ID
+	}
+	return notificationId
+}
+
+// 获取namespace当前的releaseKey
+func (w *Watcher) ReleaseKey(namespaceName string) string {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.releaseKeys[namespaceName]
+}
+
+// 启动长轮询协程，ctx取消或调用Stop后退出
+func (w *Watcher) Start(ctx context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.notificationsMap) == 0 {
+		return errors.New("NotificationsMap is empty")
+	}
+	if w.done != nil {
+		return errors.New("Watcher is already started")
+	}
+	ctx, w.cancel = context.WithCancel(ctx)
+	w.done = make(chan struct{})
+	go w.run(ctx, w.done)
+	return nil
+}
+
+// 停止长轮询并等待协程退出
+func (w *Watcher) Stop() {
+	w.mu.Lock()
+	cancel, done := w.cancel, w.done
+	w.cancel, w.done = nil, nil
+	w.mu.Unlock()
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
+
+// 长轮询主循环
+func (w *Watcher) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+	failures := 0
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+		err := w.poll(ctx)
+		if err == nil {
+			failures = 0
+			continue
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		w.emitError(err)
+		failures++
+		if sleepWithContext(ctx, w.retryDelay(failures)) != nil {
+			return
+		}
+	}
+}
+
+// 长轮询连续失败后的重连间隔，客户端配置了重试策略时按指数退避计算，计算结果小于等于0时使用RetryInterval
+func (w *Watcher) retryDelay(failures int) time.Duration {
+	if w.Client.RetryPolicy != nil {
+		if delay := w.Client.RetryPolicy.Backoff(failures); delay > 0 {
+			return delay
+		}
+	}
+	return w.RetryInterval
+}
+
+// 发起一次长轮询，并拉取有变更的namespace配置
+func (w *Watcher) poll(ctx context.Context) error {
+	notifications, _, err := w.Client.Notifications(w.copyNotificationsMap()).GetWithContext(ctx)
+	if err != nil {
+		w.loadCache()
+		return err
+	}
+	var lastErr error
+	for _, notification := range *notifications {
+		if err = w.fetch(ctx, notification); err != nil {
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+// 拉取单个namespace的最新配置，成功后再更新notificationId
+func (w *Watcher) fetch(ctx context.Context, notification Notification) error {
+	namespaceName := notification.NamespaceName
+	cp := w.Client.Configs(namespaceName)
+	cp.ReleaseKey = w.ReleaseKey(namespaceName)
+	if notification.Messages != nil {
+		messages, err := json.Marshal(notification.Messages)
+		if err != nil {
+			return err
+		}
+		cp.Messages = string(messages)
+	}
+
+	configs, info, err := cp.getFromServer(ctx)
+	if err != nil {
+		w.loadCache()
+		return err
+	}
+
+	w.mu.Lock()
+	w.notificationsMap[namespaceName] = notification.NotificationId
+	if !info.IsDataNotModified() {
+		w.releaseKeys[namespaceName] = configs.ReleaseKey
+	}
+	handlers := w.updateHandlers
+	w.mu.Unlock()
+
+	//配置未变更不触发回调
+	if info.IsDataNotModified() {
+		return nil
+	}
+	w.Client.applyConfigs(configs)
+	for _, handler := range handlers {
+		handler(configs)
+	}
+	return nil
+}
+
+// 服务端不可用时，从本地缓存加载仓库中还没有的namespace，保证离线启动时也有配置可读
+func (w *Watcher) loadCache() {
+	if w.Client.Cache == nil {
+		return
+	}
+	for namespaceName := range w.copyNotificationsMap() {
+		if _, ok := w.Client.Repository().Get(namespaceName); ok {
+			continue
+		}
+		configs, err := w.Client.loadCache(namespaceName)
+		if err != nil {
+			w.emitError(err)
+			continue
+		}
+		w.Client.applyConfigs(configs)
+	}
+}
+
+// 复制一份notificationsMap，避免长轮询期间并发读写
+func (w *Watcher) copyNotificationsMap() map[string]int64 {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	nm := make(map[string]int64, len(w.notificationsMap))
+	for namespaceName, notificationId := range w.notificationsMap {
+		nm[namespaceName] = notificationId
+	}
+	return nm
+}
+
+// 输出错误日志并触发错误回调
+func (w *Watcher) emitError(err error) {
+	w.Client.logf("Apollo watcher error: %v", err)
+	w.mu.RLock()
+	handlers := w.errorHandlers
+	w.mu.RUnlock()
+	for _, handler := range handlers {
+		handler(err)
+	}
+}
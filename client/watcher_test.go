@@ -0,0 +1,148 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/flylan/apollo-config-lib/apollotest"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWatcher(t *testing.T) {
+	server := apollotest.NewServer()
+	defer server.Close()
+	releaseKey := server.Publish(testAppId, DEFAULT_CLUSTER_NAME, "application", Configurations{"timeout": "100"})
+
+	client, err := NewClient(server.URL, testAppId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	updates := make(chan *Configs, 10)
+	watcher := client.Watcher("application")
+	watcher.OnUpdate(func(configs *Configs) { updates <- configs })
+	watcher.OnError(func(err error) { t.Error(err) })
+	if err = watcher.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Stop()
+	if err = watcher.Start(context.Background()); err == nil {
+		t.Fatal("Watcher.Start should return error when already started")
+	}
+
+	configs := waitConfigs(t, updates)
+	if configs.Configurations["timeout"] != "100" || configs.ReleaseKey != releaseKey {
+		t.Fatal(fmt.Sprintf("unexpected configs: %v", configs))
+	}
+	if watcher.NotificationId("application") != 1 || watcher.ReleaseKey("application") != releaseKey {
+		t.Fatal("Watcher should track notificationId and releaseKey")
+	}
+
+	releaseKey = server.Publish(testAppId, DEFAULT_CLUSTER_NAME, "application", Configurations{"timeout": "200"})
+	configs = waitConfigs(t, updates)
+	if configs.Configurations["timeout"] != "200" || configs.ReleaseKey != releaseKey {
+		t.Fatal(fmt.Sprintf("unexpected configs: %v", configs))
+	}
+	if watcher.NotificationId("application") != 2 {
+		t.Fatal("Watcher should update notificationId after change")
+	}
+}
+
+func TestWatcherStop(t *testing.T) {
+	server := apollotest.NewServer()
+	defer server.Close()
+
+	client, err := NewClient(server.URL, testAppId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = client.Watcher().Start(context.Background()); err == nil {
+		t.Fatal("Watcher.Start should return error when NotificationsMap is empty")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	watcher := client.Watcher("application")
+	if err = watcher.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	stopped := make(chan struct{})
+	go func() {
+		watcher.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Watcher.Stop did not return")
+	}
+}
+
+func TestWatcherStopDuringLongPoll(t *testing.T) {
+	polling := make(chan struct{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case polling <- struct{}{}:
+		default:
+		}
+		select {
+		case <-r.Context().Done():
+		case <-time.After(30 * time.Second):
+		}
+		w.WriteHeader(http.StatusNotModified)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "apollo-client-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	watcher := client.Watcher("application")
+	watcher.OnError(func(err error) { t.Error(err) })
+	if err = watcher.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	<-polling
+
+	start := time.Now()
+	watcher.Stop()
+	if time.Since(start) > 5*time.Second {
+		t.Fatal("Watcher.Stop should cancel the pending long poll")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err = client.Configs("application").GetWithContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatal(fmt.Sprintf("GetWithContext should return context error, err: %v", err))
+	}
+	if _, _, err = client.Notifications("application").GetWithContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatal(fmt.Sprintf("GetWithContext should return context error, err: %v", err))
+	}
+}
+
+func waitConfigs(t *testing.T, updates chan *Configs) *Configs {
+	select {
+	case configs := <-updates:
+		return configs
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for configs update")
+	}
+	return nil
+}
+
+func TestWatcherRetryDelay(t *testing.T) {
+	client := testNewClient(t)
+	watcher := client.Watcher("application")
+	if delay := watcher.retryDelay(1); delay != DEFAULT_WATCH_RETRY_INTERVAL {
+		t.Fatal(fmt.Sprintf("retryDelay without RetryPolicy should be RetryInterval, but: %v", delay))
+	}
+	client.RetryPolicy = &RetryPolicy{MaxAttempts: 3, Jitter: 1}
+	for i := 0; i < 100; i++ {
+		if delay := watcher.retryDelay(1); delay <= 0 {
+			t.Fatal(fmt.Sprintf("retryDelay should be positive, but: %v", delay))
+		}
+	}
+}
@@ -2,4 +2,6 @@ module github.com/flylan/apollo-config-lib
 
 go 1.18
 
-replace github.com/flylan/apollo-config-lib => ../apollo-config-lib
\ No newline at end of file
+replace github.com/flylan/apollo-config-lib => ../apollo-config-lib
+
+require gopkg.in/yaml.v3 v3.0.1
@@ -0,0 +1,244 @@
+// Package openapi 封装了Apollo Portal的Open API，用于管理应用、namespace、配置项以及发布
+package openapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/flylan/apollo-config-lib/request"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	API_PREFIX = "/openapi/v1"
+
+	HTTP_HEADER_AUTHORIZATION = "Authorization"
+	HTTP_HEADER_CONTENT_TYPE  = "Content-Type"
+	CONTENT_TYPE_JSON         = "application/json;charset=UTF-8"
+
+	DEFAULT_TIMEOUT = 10 * time.Second
+)
+
+// 与request包共用的错误，可以通过 errors.Is 或 errors.As 判断
+var (
+	ErrNotFound        = request.ErrNotFound
+	ErrUnauthorized    = request.ErrUnauthorized
+	ErrInvalidResponse = request.ErrInvalidResponse
+)
+
+type HTTPError = request.HTTPError
+
+var defaultHttpClient = request.NewHttpClient(nil)
+
+// Open API客户端
+type Client struct {
+	PortalUrl string
+	//在Portal中创建的第三方应用的token
+	Token string
+	//写操作的操作人（Apollo的用户名），必须是有权限的用户
+	Operator   string
+	Timeout    time.Duration
+	HttpClient *http.Client
+}
+
+// 客户端构建选项
+type Option func(c *Client)
+
+// 构建Open API客户端
+func NewClient(portalUrl, token string, opts ...Option) (*Client, error) {
+	if portalUrl == "" {
+		return nil, errors.New("PortalUrl is empty")
+	}
+	if token == "" {
+		return nil, errors.New("Token is empty")
+	}
+	c := &Client{
+		PortalUrl: strings.TrimSuffix(portalUrl, "/"),
+		Token:     token,
+		Timeout:   DEFAULT_TIMEOUT,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// 指定写操作的操作人
+func WithOperator(operator string) Option {
+	return func(c *Client) {
+		c.Operator = operator
+	}
+}
+
+// 指定请求超时时间
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.Timeout = timeout
+	}
+}
+
+// 指定发送请求使用的http.Client
+func WithHttpClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.HttpClient = httpClient
+	}
+}
+
+// 指定发送请求使用的RoundTripper
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *Client) {
+		c.HttpClient = request.NewHttpClient(transport)
+	}
+}
+
+// 获取应用列表，appIds为空时返回所有有权限的应用
+func (c *Client) Apps(ctx context.Context, appIds ...string) ([]*App, error) {
+	query := url.Values{}
+	if len(appIds) > 0 {
+		query.Set("appIds", strings.Join(appIds, ","))
+	}
+	var apps []*App
+	err := c.do(ctx, http.MethodGet, "/apps", query, nil, &apps)
+	return apps, err
+}
+
+// 获取应用在各个环境下的集群
+func (c *Client) EnvClusters(ctx context.Context, appId string) ([]*EnvClusters, error) {
+	var envClusters []*EnvClusters
+	err := c.do(ctx, http.MethodGet, buildPath("apps", appId, "envclusters"), nil, nil, &envClusters)
+	return envClusters, err
+}
+
+// 获取集群信息
+func (c *Client) Cluster(ctx context.Context, env, appId, clusterName string) (*Cluster, error) {
+	cluster := &Cluster{}
+	err := c.do(ctx, http.MethodGet, buildPath("envs", env, "apps", appId, "clusters", clusterName), nil, nil, cluster)
+	if err != nil {
+		return nil, err
+	}
+	return cluster, nil
+}
+
+// 获取集群下的所有namespace（包含配置项）
+func (c *Client) Namespaces(ctx context.Context, env, appId, clusterName string) ([]*Namespace, error) {
+	var namespaces []*Namespace
+	err := c.do(ctx, http.MethodGet, buildPath("envs", env, "apps", appId, "clusters", clusterName, "namespaces"), nil, nil, &namespaces)
+	return namespaces, err
+}
+
+// 创建namespace，DataChangeCreatedBy为空时使用Operator
+func (c *Client) CreateNamespace(ctx context.Context, appNamespace *AppNamespace) (*AppNamespace, error) {
+	if appNamespace.DataChangeCreatedBy == "" {
+		operator, err := c.operator()
+		if err != nil {
+			return nil, err
+		}
+		appNamespace.DataChangeCreatedBy = operator
+	}
+	created := &AppNamespace{}
+	err := c.do(ctx, http.MethodPost, buildPath("apps", appNamespace.AppId, "appnamespaces"), nil, appNamespace, created)
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// 回滚发布，回滚后配置回到该发布的上一个版本
+func (c *Client) Rollback(ctx context.Context, env string, releaseId int64) error {
+	operator, err := c.operator()
+	if err != nil {
+		return err
+	}
+	query := url.Values{"operator": {operator}}
+	return c.do(ctx, http.MethodPut, buildPath("envs", env, "releases", strconv.FormatInt(releaseId, 10), "rollback"), query, nil, nil)
+}
+
+// 发送请求，body不为nil时以json发送，result不为nil时将响应体解析到result，非2xx状态码返回HTTPError
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, result interface{}) error {
+	requestUrl := c.PortalUrl + API_PREFIX + path
+	if len(query) > 0 {
+		requestUrl += "?" + query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, requestUrl, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set(HTTP_HEADER_AUTHORIZATION, c.Token)
+	if body != nil {
+		req.Header.Set(HTTP_HEADER_CONTENT_TYPE, CONTENT_TYPE_JSON)
+	}
+
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	info := &request.Info{
+		RequestUrl:      requestUrl,
+		RequestHeaders:  req.Header,
+		StatusCode:      resp.StatusCode,
+		ResponseHeaders: resp.Header,
+		ResponseBody:    data,
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return request.NewHTTPError(info)
+	}
+	if result == nil || len(data) == 0 {
+		return nil
+	}
+	if err = json.Unmarshal(data, result); err != nil {
+		return request.NewDecodeError(info, err)
+	}
+	return nil
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HttpClient != nil {
+		return c.HttpClient
+	}
+	return defaultHttpClient
+}
+
+// 获取写操作的操作人，未配置时返回错误
+func (c *Client) operator() (string, error) {
+	if c.Operator == "" {
+		return "", errors.New("Operator is empty")
+	}
+	return c.Operator, nil
+}
+
+// 拼接请求路径，每一段都会进行转义
+func buildPath(segments ...string) string {
+	var builder strings.Builder
+	for _, segment := range segments {
+		builder.WriteString("/")
+		builder.WriteString(url.PathEscape(segment))
+	}
+	return builder.String()
+}
@@ -0,0 +1,156 @@
+package openapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/flylan/apollo-config-lib/apollotest"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+const (
+	testAppId    = "openapi-test"
+	testToken    = "token"
+	testOperator = "apollo"
+)
+
+// 启动测试用的config service和Portal，并创建测试应用（default和gray两个集群）
+func testNewPortal(t *testing.T) (*apollotest.Server, *apollotest.Portal, *Client) {
+	server := apollotest.NewServer()
+	portal := apollotest.NewPortal(server, testToken)
+	portal.CreateApp(testAppId, "OpenAPI Test", "default", "gray")
+
+	client, err := NewClient(portal.URL, testToken, WithOperator(testOperator))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return server, portal, client
+}
+
+func TestNewClient(t *testing.T) {
+	if _, err := NewClient("", testToken); err == nil {
+		t.Fatal("empty portal url should return error")
+	}
+	if _, err := NewClient("http://127.0.0.1", ""); err == nil {
+		t.Fatal("empty token should return error")
+	}
+	client, err := NewClient("http://127.0.0.1/", testToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.PortalUrl != "http://127.0.0.1" || client.Timeout != DEFAULT_TIMEOUT {
+		t.Fatal(fmt.Sprintf("unexpected client: %+v", client))
+	}
+}
+
+func TestApps(t *testing.T) {
+	server, portal, client := testNewPortal(t)
+	defer server.Close()
+	defer portal.Close()
+	portal.CreateApp("another", "Another")
+	ctx := context.Background()
+
+	apps, err := client.Apps(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(apps) != 2 || apps[0].AppId != "another" || apps[1].AppId != testAppId || apps[1].Name != "OpenAPI Test" {
+		t.Fatal(fmt.Sprintf("unexpected apps: %+v", apps))
+	}
+	if apps, err = client.Apps(ctx, testAppId); err != nil || len(apps) != 1 || apps[0].AppId != testAppId {
+		t.Fatal(fmt.Sprintf("unexpected apps: %+v, err: %v", apps, err))
+	}
+
+	envClusters, err := client.EnvClusters(ctx, testAppId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(envClusters) != 1 || envClusters[0].Env != "DEV" || fmt.Sprint(envClusters[0].Clusters) != "[default gray]" {
+		t.Fatal(fmt.Sprintf("unexpected env clusters: %+v", envClusters))
+	}
+	cluster, err := client.Cluster(ctx, "DEV", testAppId, "gray")
+	if err != nil || cluster.Name != "gray" || cluster.AppId != testAppId {
+		t.Fatal(fmt.Sprintf("unexpected cluster: %+v, err: %v", cluster, err))
+	}
+	if _, err = client.Cluster(ctx, "DEV", testAppId, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatal(fmt.Sprintf("missing cluster should return ErrNotFound, err: %v", err))
+	}
+}
+
+func TestCreateNamespace(t *testing.T) {
+	server, portal, client := testNewPortal(t)
+	defer server.Close()
+	defer portal.Close()
+	ctx := context.Background()
+
+	created, err := client.CreateNamespace(ctx, &AppNamespace{Name: "redis", AppId: testAppId, Format: FORMAT_YAML})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.Name != "redis.yaml" || created.DataChangeCreatedBy != testOperator {
+		t.Fatal(fmt.Sprintf("unexpected namespace: %+v", created))
+	}
+	namespaces, err := client.Namespaces(ctx, "DEV", testAppId, "gray")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(namespaces) != 2 || namespaces[0].NamespaceName != "application" || namespaces[1].NamespaceName != "redis.yaml" || namespaces[1].Format != FORMAT_YAML {
+		t.Fatal(fmt.Sprintf("unexpected namespaces: %+v", namespaces))
+	}
+
+	var httpErr *HTTPError
+	_, err = client.CreateNamespace(ctx, &AppNamespace{Name: "redis", AppId: testAppId, Format: FORMAT_YAML})
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusBadRequest {
+		t.Fatal(fmt.Sprintf("duplicate namespace should return 400, err: %v", err))
+	}
+
+	client.Operator = ""
+	if _, err = client.CreateNamespace(ctx, &AppNamespace{Name: "mysql", AppId: testAppId}); err == nil {
+		t.Fatal("empty operator should return error")
+	}
+}
+
+func TestUnauthorized(t *testing.T) {
+	server, portal, _ := testNewPortal(t)
+	defer server.Close()
+	defer portal.Close()
+	client, err := NewClient(portal.URL, "bad-token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var httpErr *HTTPError
+	if _, err = client.Apps(context.Background()); !errors.Is(err, ErrUnauthorized) || !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusUnauthorized {
+		t.Fatal(fmt.Sprintf("bad token should return ErrUnauthorized, err: %v", err))
+	}
+}
+
+func TestInvalidResponse(t *testing.T) {
+	client, err := NewClient("http://portal", testToken, WithTransport(roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		if r.Header.Get(HTTP_HEADER_AUTHORIZATION) != testToken {
+			t.Fatal(fmt.Sprintf("unexpected authorization: %s", r.Header.Get(HTTP_HEADER_AUTHORIZATION)))
+		}
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: http.NoBody}, nil
+	})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = client.Apps(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	client.HttpClient.Transport = roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader("{invalid"))}, nil
+	})
+	if _, err = client.Apps(context.Background()); !errors.Is(err, ErrInvalidResponse) {
+		t.Fatal(fmt.Sprintf("invalid json should return ErrInvalidResponse, err: %v", err))
+	}
+}
+
+type roundTripperFunc func(r *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
@@ -0,0 +1,117 @@
+package openapi
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// 灰度规则，命中任意一条规则的客户端会读取到灰度配置
+type GrayReleaseRule struct {
+	AppId         string                 `json:"appId"`
+	ClusterName   string                 `json:"clusterName"`
+	NamespaceName string                 `json:"namespaceName"`
+	BranchName    string                 `json:"branchName"`
+	RuleItems     []*GrayReleaseRuleItem `json:"ruleItems"`
+	Audit
+}
+
+// 单条灰度规则，ClientIpList支持通配符 *，ClientLabelList对应客户端的Label
+type GrayReleaseRuleItem struct {
+	ClientAppId     string   `json:"clientAppId"`
+	ClientIpList    []string `json:"clientIpList"`
+	ClientLabelList []string `json:"clientLabelList"`
+}
+
+// 创建灰度分支，返回的Namespace.ClusterName为分支名
+func (np *NamespaceParam) CreateBranch(ctx context.Context) (*Namespace, error) {
+	operator, err := np.Client.operator()
+	if err != nil {
+		return nil, err
+	}
+	branch := &Namespace{}
+	if err = np.Client.do(ctx, http.MethodPost, np.path("branches"), url.Values{"operator": {operator}}, nil, branch); err != nil {
+		return nil, err
+	}
+	return branch, nil
+}
+
+// 获取灰度分支，没有分支时返回nil
+func (np *NamespaceParam) Branch(ctx context.Context) (*Namespace, error) {
+	var branch *Namespace
+	if err := np.Client.do(ctx, http.MethodGet, np.path("branches"), nil, nil, &branch); err != nil {
+		return nil, err
+	}
+	return branch, nil
+}
+
+// 构建灰度分支的操作实例，用于读写灰度配置项
+func (np *NamespaceParam) BranchNamespace(branchName string) *NamespaceParam {
+	return np.Client.Namespace(np.Env, np.AppId, branchName, np.NamespaceName)
+}
+
+// 删除（放弃）灰度分支，已发布的灰度配置同时失效
+func (np *NamespaceParam) DeleteBranch(ctx context.Context, branchName string) error {
+	operator, err := np.Client.operator()
+	if err != nil {
+		return err
+	}
+	return np.Client.do(ctx, http.MethodDelete, np.path("branches", branchName), url.Values{"operator": {operator}}, nil, nil)
+}
+
+// 获取灰度规则
+func (np *NamespaceParam) GrayRules(ctx context.Context, branchName string) (*GrayReleaseRule, error) {
+	rule := &GrayReleaseRule{}
+	if err := np.Client.do(ctx, http.MethodGet, np.path("branches", branchName, "rules"), nil, nil, rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// 更新灰度规则（整体替换），已有灰度发布时立即生效，规则中未指定ClientAppId时使用AppId
+func (np *NamespaceParam) UpdateGrayRules(ctx context.Context, branchName string, ruleItems ...*GrayReleaseRuleItem) error {
+	operator, err := np.Client.operator()
+	if err != nil {
+		return err
+	}
+	for _, item := range ruleItems {
+		if item.ClientAppId == "" {
+			item.ClientAppId = np.AppId
+		}
+	}
+	rule := &GrayReleaseRule{
+		AppId:         np.AppId,
+		ClusterName:   np.ClusterName,
+		NamespaceName: np.NamespaceName,
+		BranchName:    branchName,
+		RuleItems:     ruleItems,
+		Audit:         Audit{DataChangeCreatedBy: operator, DataChangeLastModifiedBy: operator},
+	}
+	return np.Client.do(ctx, http.MethodPut, np.path("branches", branchName, "rules"), url.Values{"operator": {operator}}, rule, nil)
+}
+
+// 灰度发布分支的配置项，ReleasedBy为空时使用Operator
+func (np *NamespaceParam) PublishGray(ctx context.Context, branchName string, req *ReleaseRequest) (*Release, error) {
+	if err := np.fillReleasedBy(req); err != nil {
+		return nil, err
+	}
+	release := &Release{}
+	if err := np.Client.do(ctx, http.MethodPost, np.path("branches", branchName, "releases"), nil, req, release); err != nil {
+		return nil, err
+	}
+	return release, nil
+}
+
+// 将灰度分支的配置项合并到主版本并发布，deleteBranch为true时合并后删除分支
+func (np *NamespaceParam) Merge(ctx context.Context, branchName string, deleteBranch bool, req *ReleaseRequest) (*Release, error) {
+	if err := np.fillReleasedBy(req); err != nil {
+		return nil, err
+	}
+	query := url.Values{"deleteBranch": {strconv.FormatBool(deleteBranch)}}
+	release := &Release{}
+	if err := np.Client.do(ctx, http.MethodPost, np.path("branches", branchName, "merge"), query, req, release); err != nil {
+		return nil, err
+	}
+	return release, nil
+}
@@ -0,0 +1,144 @@
+package openapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	apollo "github.com/flylan/apollo-config-lib/client"
+	"testing"
+)
+
+func TestGrayRelease(t *testing.T) {
+	server, portal, client := testNewPortal(t)
+	defer server.Close()
+	defer portal.Close()
+	ctx := context.Background()
+	namespace := client.Namespace("DEV", testAppId, "default", "application")
+	main := testPublishReleases(t, namespace, "100")[0]
+
+	apolloClient, err := apollo.NewClient(server.URL, testAppId, apollo.WithIp([]byte{10, 0, 0, 1}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	get := func(label string) *apollo.Configs {
+		configsParam := apolloClient.Configs("application")
+		configsParam.Label = label
+		configs, _, err := configsParam.Get()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return configs
+	}
+
+	branch, err := namespace.Branch(ctx)
+	if err != nil || branch != nil {
+		t.Fatal(fmt.Sprintf("namespace without branch should return nil, branch: %+v, err: %v", branch, err))
+	}
+	if branch, err = namespace.CreateBranch(ctx); err != nil {
+		t.Fatal(err)
+	}
+	branchName := branch.ClusterName
+	if branchName == "" || branch.NamespaceName != "application" {
+		t.Fatal(fmt.Sprintf("unexpected branch: %+v", branch))
+	}
+	if branch, err = namespace.Branch(ctx); err != nil || branch.ClusterName != branchName {
+		t.Fatal(fmt.Sprintf("unexpected branch: %+v, err: %v", branch, err))
+	}
+
+	if err = namespace.UpdateGrayRules(ctx, branchName, &GrayReleaseRuleItem{ClientLabelList: []string{"canary"}}); err != nil {
+		t.Fatal(err)
+	}
+	rule, err := namespace.GrayRules(ctx, branchName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rule.BranchName != branchName || len(rule.RuleItems) != 1 || rule.RuleItems[0].ClientAppId != testAppId || fmt.Sprint(rule.RuleItems[0].ClientLabelList) != "[canary]" {
+		t.Fatal(fmt.Sprintf("unexpected gray rule: %+v", rule))
+	}
+
+	grayNamespace := namespace.BranchNamespace(branchName)
+	if _, err = grayNamespace.CreateItem(ctx, &Item{Key: "retry", Value: "3"}); err != nil {
+		t.Fatal(err)
+	}
+	gray, err := namespace.PublishGray(ctx, branchName, &ReleaseRequest{ReleaseTitle: "gray"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(gray.Configurations) != "map[retry:3 timeout:100]" {
+		t.Fatal(fmt.Sprintf("gray release should inherit main configurations, release: %+v", gray))
+	}
+	if configs := get("canary"); !gray.Matches(configs.Configurations) {
+		t.Fatal(fmt.Sprintf("canary client should read gray configs: %+v", configs))
+	}
+	if configs := get(""); !main.Matches(configs.Configurations) {
+		t.Fatal(fmt.Sprintf("other clients should read main configs: %+v", configs))
+	}
+
+	if err = namespace.UpdateGrayRules(ctx, branchName, &GrayReleaseRuleItem{ClientIpList: []string{"10.0.0.1"}}); err != nil {
+		t.Fatal(err)
+	}
+	if configs := get(""); !gray.Matches(configs.Configurations) {
+		t.Fatal(fmt.Sprintf("updated gray rule should take effect immediately: %+v", configs))
+	}
+
+	merged, err := namespace.Merge(ctx, branchName, true, &ReleaseRequest{ReleaseTitle: "merge"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(merged.Configurations) != "map[retry:3 timeout:100]" {
+		t.Fatal(fmt.Sprintf("unexpected merged release: %+v", merged))
+	}
+	if configs := get("canary"); !merged.Matches(configs.Configurations) {
+		t.Fatal(fmt.Sprintf("all clients should read merged configs: %+v", configs))
+	}
+	if branch, err = namespace.Branch(ctx); err != nil || branch != nil {
+		t.Fatal(fmt.Sprintf("branch should be deleted after merge, branch: %+v, err: %v", branch, err))
+	}
+}
+
+func TestDeleteBranch(t *testing.T) {
+	server, portal, client := testNewPortal(t)
+	defer server.Close()
+	defer portal.Close()
+	ctx := context.Background()
+	namespace := client.Namespace("DEV", testAppId, "default", "application")
+	main := testPublishReleases(t, namespace, "100")[0]
+
+	branch, err := namespace.CreateBranch(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = namespace.CreateBranch(ctx); err == nil {
+		t.Fatal("create branch twice should return error")
+	}
+	if err = namespace.UpdateGrayRules(ctx, branch.ClusterName, &GrayReleaseRuleItem{ClientIpList: []string{"*"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err = namespace.BranchNamespace(branch.ClusterName).UpdateItem(ctx, &Item{Key: "timeout", Value: "200"}, true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = namespace.PublishGray(ctx, branch.ClusterName, &ReleaseRequest{ReleaseTitle: "gray"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = namespace.DeleteBranch(ctx, branch.ClusterName); err != nil {
+		t.Fatal(err)
+	}
+	apolloClient, err := apollo.NewClient(server.URL, testAppId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	configs, _, err := apolloClient.Configs("application").Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !main.Matches(configs.Configurations) {
+		t.Fatal(fmt.Sprintf("deleted branch should not affect clients: %+v", configs))
+	}
+	if _, err = namespace.GrayRules(ctx, branch.ClusterName); !errors.Is(err, ErrNotFound) {
+		t.Fatal(fmt.Sprintf("deleted branch should return ErrNotFound, err: %v", err))
+	}
+	if _, err = namespace.BranchNamespace(branch.ClusterName).Get(ctx); !errors.Is(err, ErrNotFound) {
+		t.Fatal(fmt.Sprintf("deleted branch namespace should return ErrNotFound, err: %v", err))
+	}
+}
@@ -0,0 +1,117 @@
+package openapi
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// 某个环境、集群下的namespace，用于读写配置项和发布
+type NamespaceParam struct {
+	Client                                 *Client
+	Env, AppId, ClusterName, NamespaceName string
+}
+
+// 构建一个namespace操作实例
+func (c *Client) Namespace(env, appId, clusterName, namespaceName string) *NamespaceParam {
+	return &NamespaceParam{
+		Client:        c,
+		Env:           env,
+		AppId:         appId,
+		ClusterName:   clusterName,
+		NamespaceName: namespaceName,
+	}
+}
+
+// 获取namespace信息及其配置项
+func (np *NamespaceParam) Get(ctx context.Context) (*Namespace, error) {
+	namespace := &Namespace{}
+	if err := np.Client.do(ctx, http.MethodGet, np.path(), nil, nil, namespace); err != nil {
+		return nil, err
+	}
+	return namespace, nil
+}
+
+// 获取配置项，不存在时返回的错误满足 errors.Is(err, ErrNotFound)
+func (np *NamespaceParam) Item(ctx context.Context, key string) (*Item, error) {
+	item := &Item{}
+	if err := np.Client.do(ctx, http.MethodGet, np.path("items", key), nil, nil, item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// 新增配置项，DataChangeCreatedBy为空时使用Operator
+func (np *NamespaceParam) CreateItem(ctx context.Context, item *Item) (*Item, error) {
+	if item.DataChangeCreatedBy == "" {
+		operator, err := np.Client.operator()
+		if err != nil {
+			return nil, err
+		}
+		item.DataChangeCreatedBy = operator
+	}
+	created := &Item{}
+	if err := np.Client.do(ctx, http.MethodPost, np.path("items"), nil, item, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// 修改配置项，createIfNotExists为true时配置项不存在会自动创建，DataChangeLastModifiedBy为空时使用Operator
+func (np *NamespaceParam) UpdateItem(ctx context.Context, item *Item, createIfNotExists bool) error {
+	if item.DataChangeLastModifiedBy == "" {
+		operator, err := np.Client.operator()
+		if err != nil {
+			return err
+		}
+		item.DataChangeLastModifiedBy = operator
+	}
+	if createIfNotExists && item.DataChangeCreatedBy == "" {
+		item.DataChangeCreatedBy = item.DataChangeLastModifiedBy
+	}
+	var query url.Values
+	if createIfNotExists {
+		query = url.Values{"createIfNotExists": {"true"}}
+	}
+	return np.Client.do(ctx, http.MethodPut, np.path("items", item.Key), query, item, nil)
+}
+
+// 删除配置项
+func (np *NamespaceParam) DeleteItem(ctx context.Context, key string) error {
+	operator, err := np.Client.operator()
+	if err != nil {
+		return err
+	}
+	return np.Client.do(ctx, http.MethodDelete, np.path("items", key), url.Values{"operator": {operator}}, nil, nil)
+}
+
+// 发布namespace当前的所有配置项，ReleasedBy为空时使用Operator
+func (np *NamespaceParam) Publish(ctx context.Context, req *ReleaseRequest) (*Release, error) {
+	if err := np.fillReleasedBy(req); err != nil {
+		return nil, err
+	}
+	release := &Release{}
+	if err := np.Client.do(ctx, http.MethodPost, np.path("releases"), nil, req, release); err != nil {
+		return nil, err
+	}
+	return release, nil
+}
+
+func (np *NamespaceParam) fillReleasedBy(req *ReleaseRequest) error {
+	if req.ReleasedBy != "" {
+		return nil
+	}
+	operator, err := np.Client.operator()
+	if err != nil {
+		return err
+	}
+	req.ReleasedBy = operator
+	return nil
+}
+
+// 构建namespace下的请求路径
+func (np *NamespaceParam) path(segments ...string) string {
+	return buildPath(append([]string{
+		"envs", np.Env, "apps", np.AppId, "clusters", np.ClusterName, "namespaces", np.NamespaceName,
+	}, segments...)...)
+}
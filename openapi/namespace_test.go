@@ -0,0 +1,117 @@
+package openapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	apollo "github.com/flylan/apollo-config-lib/client"
+	"testing"
+)
+
+func TestNamespaceItems(t *testing.T) {
+	server, portal, client := testNewPortal(t)
+	defer server.Close()
+	defer portal.Close()
+	ctx := context.Background()
+	namespace := client.Namespace("DEV", testAppId, "default", "application")
+
+	created, err := namespace.CreateItem(ctx, &Item{Key: "timeout", Value: "100", Comment: "ms"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.Key != "timeout" || created.Value != "100" || created.DataChangeCreatedBy != testOperator {
+		t.Fatal(fmt.Sprintf("unexpected item: %+v", created))
+	}
+	if err = namespace.UpdateItem(ctx, &Item{Key: "timeout", Value: "200"}, false); err != nil {
+		t.Fatal(err)
+	}
+	if err = namespace.UpdateItem(ctx, &Item{Key: "missing", Value: "1"}, false); !errors.Is(err, ErrNotFound) {
+		t.Fatal(fmt.Sprintf("update missing item should return ErrNotFound, err: %v", err))
+	}
+	if err = namespace.UpdateItem(ctx, &Item{Key: "retry", Value: "3"}, true); err != nil {
+		t.Fatal(err)
+	}
+
+	item, err := namespace.Item(ctx, "timeout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.Value != "200" || item.DataChangeLastModifiedBy != testOperator {
+		t.Fatal(fmt.Sprintf("unexpected item: %+v", item))
+	}
+	got, err := namespace.Get(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(got.Configurations()) != "map[retry:3 timeout:200]" || got.Format != FORMAT_PROPERTIES {
+		t.Fatal(fmt.Sprintf("unexpected namespace: %+v", got))
+	}
+
+	if err = namespace.DeleteItem(ctx, "retry"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = namespace.Item(ctx, "retry"); !errors.Is(err, ErrNotFound) {
+		t.Fatal(fmt.Sprintf("deleted item should return ErrNotFound, err: %v", err))
+	}
+	if _, err = client.Namespace("DEV", testAppId, "default", "missing").Get(ctx); !errors.Is(err, ErrNotFound) {
+		t.Fatal(fmt.Sprintf("missing namespace should return ErrNotFound, err: %v", err))
+	}
+}
+
+func TestNamespacePublish(t *testing.T) {
+	server, portal, client := testNewPortal(t)
+	defer server.Close()
+	defer portal.Close()
+	ctx := context.Background()
+	namespace := client.Namespace("DEV", testAppId, "default", "application")
+
+	apolloClient, err := apollo.NewClient(server.URL, testAppId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = apolloClient.Configs("application").Get(); !errors.Is(err, apollo.ErrNamespaceNotFound) {
+		t.Fatal(fmt.Sprintf("unpublished namespace should return ErrNamespaceNotFound, err: %v", err))
+	}
+
+	if _, err = namespace.CreateItem(ctx, &Item{Key: "timeout", Value: "100"}); err != nil {
+		t.Fatal(err)
+	}
+	first, err := namespace.Publish(ctx, &ReleaseRequest{ReleaseTitle: "first"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Id == 0 || first.Name != "first" || first.DataChangeCreatedBy != testOperator || first.Configurations["timeout"] != "100" {
+		t.Fatal(fmt.Sprintf("unexpected release: %+v", first))
+	}
+	configs, _, err := apolloClient.Configs("application").Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !first.Matches(configs.Configurations) {
+		t.Fatal(fmt.Sprintf("published configs should be visible, configs: %+v", configs))
+	}
+
+	if err = namespace.UpdateItem(ctx, &Item{Key: "timeout", Value: "200"}, false); err != nil {
+		t.Fatal(err)
+	}
+	second, err := namespace.Publish(ctx, &ReleaseRequest{ReleaseTitle: "second", ReleaseComment: "slower"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if configs, _, err = apolloClient.Configs("application").Get(); err != nil || !second.Matches(configs.Configurations) {
+		t.Fatal(fmt.Sprintf("published configs should be visible, configs: %+v, err: %v", configs, err))
+	}
+
+	if err = client.Rollback(ctx, "DEV", first.Id); err == nil {
+		t.Fatal("rollback a release which is not the latest should return error")
+	}
+	if err = client.Rollback(ctx, "DEV", second.Id); err != nil {
+		t.Fatal(err)
+	}
+	if configs, _, err = apolloClient.Configs("application").Get(); err != nil || !first.Matches(configs.Configurations) || second.Matches(configs.Configurations) {
+		t.Fatal(fmt.Sprintf("rollback should restore the previous release, configs: %+v, err: %v", configs, err))
+	}
+	if err = client.Rollback(ctx, "DEV", 1000); !errors.Is(err, ErrNotFound) {
+		t.Fatal(fmt.Sprintf("missing release should return ErrNotFound, err: %v", err))
+	}
+}
@@ -0,0 +1,143 @@
+package openapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+type ValueChange struct {
+	OldValue string
+	NewValue string
+}
+
+// 两次发布之间的配置差异
+type ReleaseDiff struct {
+	BaseReleaseId   int64
+	TargetReleaseId int64
+	Added           map[string]string
+	Modified        map[string]ValueChange
+	Deleted         map[string]string
+}
+
+// 回滚结果，Current为回滚后生效的发布，可以通过 Release.Matches 与config service返回的配置对应
+type RollbackResult struct {
+	RolledBack []*Release
+	Current    *Release
+}
+
+// 获取当前生效的最新发布，namespace没有发布过时返回nil
+func (np *NamespaceParam) LatestRelease(ctx context.Context) (*Release, error) {
+	var release *Release
+	if err := np.Client.do(ctx, http.MethodGet, np.path("releases", "latest"), nil, nil, &release); err != nil {
+		return nil, err
+	}
+	return release, nil
+}
+
+// 回滚当前生效的最新发布，配置回到上一个发布
+func (np *NamespaceParam) Rollback(ctx context.Context) (*RollbackResult, error) {
+	latest, err := np.LatestRelease(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("No release to rollback for namespace %s", np.NamespaceName)
+	}
+	return np.rollback(ctx, latest, func(current *Release) bool { return true })
+}
+
+// 逐个回滚最新发布，直到target生效
+//
+// Open API的发布id是全局的，且无法查询某个发布是否已被回滚，因此target必须是该namespace的发布（由 Publish 或 LatestRelease 返回），
+// 且调用方需要保证target之后没有回滚过该发布；校验不通过时不会执行任何回滚
+func (np *NamespaceParam) RollbackTo(ctx context.Context, target *Release) (*RollbackResult, error) {
+	if err := np.verifyRelease(target); err != nil {
+		return nil, err
+	}
+	latest, err := np.LatestRelease(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if latest == nil || latest.Id <= target.Id {
+		return nil, fmt.Errorf("Release %d is not before the latest release of namespace %s", target.Id, np.NamespaceName)
+	}
+	result, err := np.rollback(ctx, latest, func(current *Release) bool {
+		return current == nil || current.Id <= target.Id
+	})
+	if err != nil {
+		return result, err
+	}
+	if result.Current == nil || result.Current.Id != target.Id {
+		return result, fmt.Errorf("Release %d is not active in namespace %s", target.Id, np.NamespaceName)
+	}
+	return result, nil
+}
+
+// 校验发布属于当前namespace
+func (np *NamespaceParam) verifyRelease(release *Release) error {
+	if release == nil || release.Id <= 0 {
+		return errors.New("Release is empty")
+	}
+	if release.AppId != np.AppId || release.ClusterName != np.ClusterName || release.NamespaceName != np.NamespaceName {
+		return fmt.Errorf("Release %d belongs to %s %s %s, not namespace %s %s %s", release.Id,
+			release.AppId, release.ClusterName, release.NamespaceName, np.AppId, np.ClusterName, np.NamespaceName)
+	}
+	return nil
+}
+
+// 从latest开始回滚，每次回滚后获取最新发布，直到done返回true
+func (np *NamespaceParam) rollback(ctx context.Context, latest *Release, done func(current *Release) bool) (*RollbackResult, error) {
+	result := &RollbackResult{}
+	for {
+		if err := np.Client.Rollback(ctx, np.Env, latest.Id); err != nil {
+			return result, err
+		}
+		result.RolledBack = append(result.RolledBack, latest)
+		current, err := np.LatestRelease(ctx)
+		if err != nil {
+			return result, err
+		}
+		result.Current = current
+		if done(current) {
+			return result, nil
+		}
+		latest = current
+	}
+}
+
+// 对比两次发布的配置，base为nil时视为空配置
+func CompareReleases(base, target *Release) *ReleaseDiff {
+	diff := &ReleaseDiff{
+		Added:    map[string]string{},
+		Modified: map[string]ValueChange{},
+		Deleted:  map[string]string{},
+	}
+	var baseConfigurations, targetConfigurations map[string]string
+	if base != nil {
+		diff.BaseReleaseId, baseConfigurations = base.Id, base.Configurations
+	}
+	if target != nil {
+		diff.TargetReleaseId, targetConfigurations = target.Id, target.Configurations
+	}
+	for key, newValue := range targetConfigurations {
+		oldValue, ok := baseConfigurations[key]
+		if !ok {
+			diff.Added[key] = newValue
+		} else if oldValue != newValue {
+			diff.Modified[key] = ValueChange{OldValue: oldValue, NewValue: newValue}
+		}
+	}
+	for key, oldValue := range baseConfigurations {
+		if _, ok := targetConfigurations[key]; !ok {
+			diff.Deleted[key] = oldValue
+		}
+	}
+	return diff
+}
+
+// 判断两次发布的配置是否完全一致
+func (d *ReleaseDiff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Modified) == 0 && len(d.Deleted) == 0
+}
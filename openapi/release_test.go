@@ -0,0 +1,141 @@
+package openapi
+
+import (
+	"context"
+	"fmt"
+	apollo "github.com/flylan/apollo-config-lib/client"
+	"testing"
+)
+
+// 依次发布每组配置，返回每次的发布结果
+func testPublishReleases(t *testing.T, namespace *NamespaceParam, values ...string) []*Release {
+	ctx := context.Background()
+	var releases []*Release
+	for i, value := range values {
+		if err := namespace.UpdateItem(ctx, &Item{Key: "timeout", Value: value}, true); err != nil {
+			t.Fatal(err)
+		}
+		release, err := namespace.Publish(ctx, &ReleaseRequest{ReleaseTitle: fmt.Sprintf("release-%d", i)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		releases = append(releases, release)
+	}
+	return releases
+}
+
+func TestLatestRelease(t *testing.T) {
+	server, portal, client := testNewPortal(t)
+	defer server.Close()
+	defer portal.Close()
+	ctx := context.Background()
+	namespace := client.Namespace("DEV", testAppId, "default", "application")
+
+	latest, err := namespace.LatestRelease(ctx)
+	if err != nil || latest != nil {
+		t.Fatal(fmt.Sprintf("namespace without release should return nil, latest: %+v, err: %v", latest, err))
+	}
+	releases := testPublishReleases(t, namespace, "100", "200")
+	if latest, err = namespace.LatestRelease(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if latest.Id != releases[1].Id || !latest.Matches(releases[1].Configurations) || latest.Name != "release-1" {
+		t.Fatal(fmt.Sprintf("unexpected latest release: %+v", latest))
+	}
+}
+
+func TestCompareReleases(t *testing.T) {
+	base := &Release{Id: 1, Configurations: map[string]string{"a": "1", "b": "2", "c": "3"}}
+	target := &Release{Id: 2, Configurations: map[string]string{"a": "1", "b": "20", "d": "4"}}
+
+	diff := CompareReleases(base, target)
+	if diff.BaseReleaseId != 1 || diff.TargetReleaseId != 2 || diff.IsEmpty() {
+		t.Fatal(fmt.Sprintf("unexpected diff: %+v", diff))
+	}
+	if fmt.Sprint(diff.Added) != "map[d:4]" || fmt.Sprint(diff.Modified) != "map[b:{2 20}]" || fmt.Sprint(diff.Deleted) != "map[c:3]" {
+		t.Fatal(fmt.Sprintf("unexpected diff: %+v", diff))
+	}
+	if diff = CompareReleases(nil, target); len(diff.Added) != 3 || diff.BaseReleaseId != 0 {
+		t.Fatal(fmt.Sprintf("nil base should be treated as empty, diff: %+v", diff))
+	}
+	if diff = CompareReleases(target, target); !diff.IsEmpty() {
+		t.Fatal(fmt.Sprintf("same release should have no diff: %+v", diff))
+	}
+}
+
+func TestRollbackTo(t *testing.T) {
+	server, portal, client := testNewPortal(t)
+	defer server.Close()
+	defer portal.Close()
+	ctx := context.Background()
+	namespace := client.Namespace("DEV", testAppId, "default", "application")
+	releases := testPublishReleases(t, namespace, "100", "200", "300", "400")
+
+	apolloClient, err := apollo.NewClient(server.URL, testAppId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := namespace.Rollback(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.RolledBack) != 1 || result.RolledBack[0].Id != releases[3].Id || result.Current.Id != releases[2].Id {
+		t.Fatal(fmt.Sprintf("unexpected rollback result: %+v", result))
+	}
+
+	if result, err = namespace.RollbackTo(ctx, releases[0]); err != nil {
+		t.Fatal(err)
+	}
+	if len(result.RolledBack) != 2 || result.Current.Id != releases[0].Id {
+		t.Fatal(fmt.Sprintf("unexpected rollback result: %+v", result))
+	}
+	configs, _, err := apolloClient.Configs("application").Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Current.Matches(configs.Configurations) || configs.Configurations["timeout"] != "100" {
+		t.Fatal(fmt.Sprintf("configs should match the current release, configs: %+v", configs))
+	}
+
+	if _, err = namespace.RollbackTo(ctx, releases[2]); err == nil {
+		t.Fatal("rollback to a release after the latest should return error")
+	}
+	if _, err = namespace.Rollback(ctx); err == nil {
+		t.Fatal("rollback the only active release should return error")
+	}
+}
+
+func TestRollbackToOtherNamespace(t *testing.T) {
+	server, portal, client := testNewPortal(t)
+	defer server.Close()
+	defer portal.Close()
+	ctx := context.Background()
+	namespace := client.Namespace("DEV", testAppId, "default", "application")
+	releases := testPublishReleases(t, namespace, "100", "200", "300")
+	other := testPublishReleases(t, client.Namespace("DEV", testAppId, "gray", "application"), "1")[0]
+
+	//其他namespace的发布、空发布以及缺少namespace信息的发布都不能执行回滚
+	for _, target := range []*Release{other, nil, {Id: releases[0].Id}} {
+		if _, err := namespace.RollbackTo(ctx, target); err == nil {
+			t.Fatal(fmt.Sprintf("release of other namespace should be rejected: %+v", target))
+		}
+	}
+	latest, err := namespace.LatestRelease(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest.Id != releases[2].Id {
+		t.Fatal(fmt.Sprintf("rejected rollback should not roll back any release, latest: %+v", latest))
+	}
+}
+
+func TestReleaseMatches(t *testing.T) {
+	release := &Release{Configurations: map[string]string{"a": "1", "b": "2"}}
+	if !release.Matches(map[string]string{"b": "2", "a": "1"}) {
+		t.Fatal("same configurations should match")
+	}
+	if release.Matches(map[string]string{"a": "1"}) || release.Matches(map[string]string{"a": "1", "b": "3"}) || release.Matches(map[string]string{"a": "1", "c": "2"}) {
+		t.Fatal("different configurations should not match")
+	}
+}
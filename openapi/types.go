@@ -0,0 +1,112 @@
+package openapi
+
+const (
+	FORMAT_PROPERTIES = "properties"
+	FORMAT_XML        = "xml"
+	FORMAT_JSON       = "json"
+	FORMAT_YML        = "yml"
+	FORMAT_YAML       = "yaml"
+	FORMAT_TXT        = "txt"
+)
+
+// 数据变更的审计信息，时间格式由Portal决定（如 2024-07-29T17:26:40.000+0800）
+type Audit struct {
+	DataChangeCreatedBy        string `json:"dataChangeCreatedBy,omitempty"`
+	DataChangeLastModifiedBy   string `json:"dataChangeLastModifiedBy,omitempty"`
+	DataChangeCreatedTime      string `json:"dataChangeCreatedTime,omitempty"`
+	DataChangeLastModifiedTime string `json:"dataChangeLastModifiedTime,omitempty"`
+}
+
+type App struct {
+	Name       string `json:"name"`
+	AppId      string `json:"appId"`
+	OrgId      string `json:"orgId,omitempty"`
+	OrgName    string `json:"orgName,omitempty"`
+	OwnerName  string `json:"ownerName,omitempty"`
+	OwnerEmail string `json:"ownerEmail,omitempty"`
+	Audit
+}
+
+type EnvClusters struct {
+	Env      string   `json:"env"`
+	Clusters []string `json:"clusters"`
+}
+
+type Cluster struct {
+	Name              string `json:"name"`
+	AppId             string `json:"appId"`
+	ParentClusterName string `json:"parentClusterName,omitempty"`
+	Audit
+}
+
+// 集群下的namespace及其配置项
+type Namespace struct {
+	AppId         string  `json:"appId"`
+	ClusterName   string  `json:"clusterName"`
+	NamespaceName string  `json:"namespaceName"`
+	Comment       string  `json:"comment,omitempty"`
+	Format        string  `json:"format"`
+	IsPublic      bool    `json:"isPublic"`
+	Items         []*Item `json:"items"`
+	Audit
+}
+
+// 应用下的namespace定义，用于创建namespace
+type AppNamespace struct {
+	Name     string `json:"name"`
+	AppId    string `json:"appId"`
+	Format   string `json:"format"`
+	IsPublic bool   `json:"isPublic"`
+	Comment  string `json:"comment,omitempty"`
+	Audit
+}
+
+type Item struct {
+	Key     string `json:"key"`
+	Value   string `json:"value"`
+	Comment string `json:"comment,omitempty"`
+	Audit
+}
+
+// 发布请求
+type ReleaseRequest struct {
+	ReleaseTitle       string `json:"releaseTitle"`
+	ReleaseComment     string `json:"releaseComment,omitempty"`
+	ReleasedBy         string `json:"releasedBy"`
+	IsEmergencyPublish bool   `json:"isEmergencyPublish,omitempty"`
+}
+
+// 发布结果，Open API不返回releaseKey，需要与config service返回的配置对应时使用 Matches
+type Release struct {
+	Id             int64             `json:"id"`
+	AppId          string            `json:"appId"`
+	ClusterName    string            `json:"clusterName"`
+	NamespaceName  string            `json:"namespaceName"`
+	Name           string            `json:"name"`
+	Comment        string            `json:"comment,omitempty"`
+	Configurations map[string]string `json:"configurations"`
+	Audit
+}
+
+// 判断config service返回的配置（如 ConfigsParam.Get 返回的Configurations）是否与该发布的配置完全一致，
+// 配置内容相同的多次发布无法区分
+func (r *Release) Matches(configurations map[string]string) bool {
+	if len(r.Configurations) != len(configurations) {
+		return false
+	}
+	for key, value := range r.Configurations {
+		if v, ok := configurations[key]; !ok || v != value {
+			return false
+		}
+	}
+	return true
+}
+
+// 获取namespace的配置项（key到value的映射）
+func (n *Namespace) Configurations() map[string]string {
+	configurations := make(map[string]string, len(n.Items))
+	for _, item := range n.Items {
+		configurations[item.Key] = item.Value
+	}
+	return configurations
+}
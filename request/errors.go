@@ -0,0 +1,77 @@
+package request
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// 响应体在错误信息中最多展示的长度
+const MAX_ERROR_BODY_LENGTH = 256
+
+var (
+	ErrNotFound        = errors.New("not found")
+	ErrUnauthorized    = errors.New("unauthorized")
+	ErrInvalidResponse = errors.New("invalid response")
+)
+
+// 服务端返回了非预期的HTTP状态码，404可以通过 errors.Is(err, ErrNotFound) 判断，401可以通过 errors.Is(err, ErrUnauthorized) 判断
+type HTTPError struct {
+	StatusCode int
+	URL        string
+	Body       []byte
+}
+
+// 响应体无法解析
+type DecodeError struct {
+	URL  string
+	Body []byte
+	Err  error
+}
+
+// 基于请求信息构建HTTPError
+func NewHTTPError(info *Info) *HTTPError {
+	return &HTTPError{StatusCode: info.StatusCode, URL: info.RequestUrl, Body: info.ResponseBody}
+}
+
+func (e *HTTPError) Error() string {
+	if len(e.Body) == 0 {
+		return fmt.Sprintf("%s returns HTTP status code: %d", e.URL, e.StatusCode)
+	}
+	return fmt.Sprintf("%s returns HTTP status code: %d, body: %s", e.URL, e.StatusCode, truncateBody(e.Body))
+}
+
+func (e *HTTPError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	}
+	return false
+}
+
+// 基于请求信息构建DecodeError
+func NewDecodeError(info *Info, err error) *DecodeError {
+	return &DecodeError{URL: info.RequestUrl, Body: info.ResponseBody, Err: err}
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("Unable to decode response of %s: %v", e.URL, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// 可以通过 errors.Is(err, ErrInvalidResponse) 判断
+func (e *DecodeError) Is(target error) bool {
+	return target == ErrInvalidResponse
+}
+
+func truncateBody(body []byte) string {
+	if len(body) > MAX_ERROR_BODY_LENGTH {
+		return string(body[:MAX_ERROR_BODY_LENGTH]) + "..."
+	}
+	return string(body)
+}
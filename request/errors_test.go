@@ -0,0 +1,36 @@
+package request
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestHTTPError(t *testing.T) {
+	info := &Info{RequestUrl: "http://config.example.com/configs", StatusCode: 404, ResponseBody: []byte(strings.Repeat("a", 300))}
+	var err error = NewHTTPError(info)
+	if !errors.Is(err, ErrNotFound) || errors.Is(err, ErrUnauthorized) {
+		t.Fatal("HTTPError with status code 404 should match ErrNotFound only")
+	}
+	if !strings.HasSuffix(err.Error(), "...") {
+		t.Fatal(fmt.Sprintf("long body should be truncated: %s", err.Error()))
+	}
+	info.StatusCode = 401
+	if err = NewHTTPError(info); !errors.Is(err, ErrUnauthorized) || errors.Is(err, ErrNotFound) {
+		t.Fatal("HTTPError with status code 401 should match ErrUnauthorized only")
+	}
+	var httpErr *HTTPError
+	if !errors.As(fmt.Errorf("wrapped: %w", err), &httpErr) || httpErr.StatusCode != 401 || httpErr.URL != info.RequestUrl {
+		t.Fatal("HTTPError should be usable with errors.As")
+	}
+}
+
+func TestDecodeError(t *testing.T) {
+	jsonErr := json.Unmarshal([]byte("{"), &map[string]string{})
+	err := fmt.Errorf("wrapped: %w", NewDecodeError(&Info{RequestUrl: "http://config.example.com"}, jsonErr))
+	if !errors.Is(err, ErrInvalidResponse) || !errors.Is(err, jsonErr) {
+		t.Fatal(fmt.Sprintf("DecodeError should match ErrInvalidResponse and the decode error, err: %v", err))
+	}
+}
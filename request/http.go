@@ -1,9 +1,8 @@
 package request
 
 import (
-	"crypto/tls"
+	"context"
 	"errors"
-	"github.com/flylan/apollo-config-lib/utils"
 	"io"
 	"net"
 	"net/http"
@@ -21,13 +20,12 @@ const (
 )
 
 var (
-	transportInsecure     *http.Transport
-	transportSecure       *http.Transport
-	transportInsecureOnce sync.Once
-	transportSecureOnce   sync.Once
+	defaultHttpClient     *http.Client
+	defaultHttpClientOnce sync.Once
 )
 
-func newHttpTransport() *http.Transport {
+// 构建一个使用默认参数的httpTransport，可以在此基础上修改代理、TLS等配置后注入
+func NewHttpTransport() *http.Transport {
 	return &http.Transport{
 		Proxy:               http.ProxyFromEnvironment,
 		MaxIdleConns:        DEFAULT_MAX_IDLE_CONNS,
@@ -39,60 +37,81 @@ func newHttpTransport() *http.Transport {
 	}
 }
 
-// 获取httpTransport
-func getTransport(insecureSkipVerify bool) *http.Transport {
-	if insecureSkipVerify {
-		transportInsecureOnce.Do(
-			func() {
-				transportInsecure = newHttpTransport()
-				transportInsecure.TLSClientConfig = &tls.Config{
-					InsecureSkipVerify: insecureSkipVerify,
-				}
-			},
-		)
-		return transportInsecure
+// 基于指定的RoundTripper构建http.Client，transport为空时使用NewHttpTransport
+func NewHttpClient(transport http.RoundTripper) *http.Client {
+	if transport == nil {
+		transport = NewHttpTransport()
 	}
+	return &http.Client{Transport: transport}
+}
 
-	transportSecureOnce.Do(
+// 获取包内默认的http.Client，只会创建一次，会校验服务端证书
+func getHttpClient() *http.Client {
+	defaultHttpClientOnce.Do(
 		func() {
-			transportSecure = newHttpTransport()
+			defaultHttpClient = NewHttpClient(nil)
 		},
 	)
-	return transportSecure
+	return defaultHttpClient
+}
+
+// 请求发送器，HttpClient为空时使用包内默认的http.Client，Signer为空时使用Apollo的HMAC签名
+type Sender struct {
+	HttpClient *http.Client
+	Signer     Signer
+}
+
+// 构建一个使用指定http.Client的请求发送器
+func NewSender(httpClient *http.Client) *Sender {
+	return &Sender{HttpClient: httpClient}
 }
 
 // 发送http GET请求
 func SendGetRequest(requestUrl, appID, secret string, timeout time.Duration, info *Info) (*Info, error) {
+	return SendGetRequestWithContext(context.Background(), requestUrl, appID, secret, timeout, info)
+}
+
+// 发送http GET请求，ctx取消或超时时请求会立即中断
+func SendGetRequestWithContext(ctx context.Context, requestUrl, appID, secret string, timeout time.Duration, info *Info) (*Info, error) {
+	return (&Sender{}).SendGetRequest(ctx, requestUrl, appID, secret, timeout, info)
+}
+
+// 发送http GET请求，timeout通过ctx生效，不会修改注入的http.Client
+func (s *Sender) SendGetRequest(ctx context.Context, requestUrl, appID, secret string, timeout time.Duration, info *Info) (*Info, error) {
 	if requestUrl == "" {
 		return info, errors.New("RequestUrl is empty")
 	}
 	info.RequestUrl = requestUrl
 
+	//设置请求超时时间
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
 	//构建一个http get请求
-	req, err := http.NewRequest(METHOD_GET, requestUrl, nil)
+	req, err := http.NewRequestWithContext(ctx, METHOD_GET, requestUrl, nil)
 	if err != nil {
 		return info, err
 	}
 	info.RequestHeaders = req.Header
 
-	//配置了秘钥就要生成相应的request headers
-	if secret != "" {
-		headers, err := buildHttpHeaders(requestUrl, appID, secret, timestamp())
-		if err != nil {
-			return info, err
-		}
-		for key, value := range headers {
-			if key != "" {
-				req.Header.Set(key, value)
-			}
-		}
+	//签名，未指定签名器时配置了秘钥就生成Apollo的签名headers
+	signer := s.Signer
+	if signer == nil {
+		signer = NewHmacSigner(appID, secret)
+	}
+	if err = signer.Sign(req); err != nil {
+		return info, err
 	}
 
 	//发起请求
-	resp, err := (&http.Client{Timeout: timeout, Transport: getTransport(req.URL.Scheme == utils.SCHEME_HTTPS)}).Do(req)
+	resp, err := s.httpClient().Do(req)
 	if err != nil {
 		return info, err
 	}
+	defer func() { _ = resp.Body.Close() }()
 	info.ResponseHeaders = resp.Header
 	info.StatusCode = resp.StatusCode
 
@@ -101,8 +120,15 @@ func SendGetRequest(requestUrl, appID, secret string, timeout time.Duration, inf
 	if err != nil {
 		return info, err
 	}
-	defer func() { _ = resp.Body.Close() }()
 	info.ResponseBody = body
 
 	return info, nil
 }
+
+// 获取发送请求的http.Client
+func (s *Sender) httpClient() *http.Client {
+	if s.HttpClient != nil {
+		return s.HttpClient
+	}
+	return getHttpClient()
+}
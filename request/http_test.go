@@ -1,16 +1,28 @@
 package request
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"github.com/flylan/apollo-config-lib/apollotest"
 	"github.com/flylan/apollo-config-lib/utils"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 )
 
 func TestHttp(t *testing.T) {
-	requestUrl := "http://81.68.181.139:8080/notifications/v2?appId=apollo-client-test&cluster=default&notifications=%5B%7B%22namespaceName%22%3A%22%22%2C%22notificationId%22%3A0%7D%2C%7B%22namespaceName%22%3A%22application%22%2C%22notificationId%22%3A-1%7D%5D"
 	appID := "apollo-client-test"
 	secret := "4081edabfe4e4ba097cc16defc526c2f"
+	server := apollotest.NewServer()
+	defer server.Close()
+	server.SetSecret(appID, secret)
+	server.Publish(appID, "default", "application", map[string]string{"timeout": "100"})
+
+	requestUrl := server.URL + "/notifications/v2?appId=apollo-client-test&cluster=default&notifications=%5B%7B%22namespaceName%22%3A%22%22%2C%22notificationId%22%3A0%7D%2C%7B%22namespaceName%22%3A%22application%22%2C%22notificationId%22%3A-1%7D%5D"
 	timeout := 10 * time.Second
 	info := &Info{}
 	info, err := SendGetRequest(requestUrl, appID, secret, timeout, info)
@@ -27,3 +39,73 @@ func TestHttp(t *testing.T) {
 		t.Fatal(fmt.Sprintf("error response: %v", info))
 	}
 }
+
+func TestHttpWithContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(10 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := SendGetRequestWithContext(ctx, server.URL, "apollo-client-test", "", 60*time.Second, &Info{})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal(fmt.Sprintf("SendGetRequestWithContext should return context error, err: %v", err))
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Fatal("SendGetRequestWithContext should return as soon as ctx is done")
+	}
+}
+
+func TestSenderWithHttpClient(t *testing.T) {
+	var requests int
+	httpClient := NewHttpClient(roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		requests++
+		if r.Header.Get(HTTP_HEADER_AUTHORIZATION) == "" {
+			t.Error("request should be signed before sent to injected transport")
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader(`[]`)),
+			Request:    r,
+		}, nil
+	}))
+	info, err := NewSender(httpClient).SendGetRequest(
+		context.Background(),
+		"https://config.example.com/notifications/v2?appId=apollo-client-test",
+		"apollo-client-test",
+		"4081edabfe4e4ba097cc16defc526c2f",
+		time.Second,
+		&Info{},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requests != 1 || !info.IsGetDataSuccess() || string(info.ResponseBody) != "[]" {
+		t.Fatal(fmt.Sprintf("request should be sent with injected http.Client, info: %v", info))
+	}
+}
+
+func TestNewHttpClient(t *testing.T) {
+	transport, ok := NewHttpClient(nil).Transport.(*http.Transport)
+	if !ok || transport.MaxIdleConnsPerHost != DEFAULT_MAX_IDLE_CONNS_PER_HOST {
+		t.Fatal("NewHttpClient should use default transport when transport is nil")
+	}
+	if NewHttpTransport() == NewHttpTransport() {
+		t.Fatal("NewHttpTransport should return a new transport every time")
+	}
+	if getHttpClient() != getHttpClient() {
+		t.Fatal("default http.Client should be created only once")
+	}
+}
+
+type roundTripperFunc func(r *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
@@ -0,0 +1,66 @@
+package request
+
+import "net/http"
+
+// 请求签名器，发送请求前调用，可以设置鉴权、请求ID等请求头
+type Signer interface {
+	Sign(req *http.Request) error
+}
+
+type SignerFunc func(req *http.Request) error
+
+// 依次执行多个签名器，任意一个失败时返回错误
+type Signers []Signer
+
+// Apollo的HMAC-SHA1签名，秘钥为空时不签名
+type HmacSigner struct {
+	AppId  string
+	Secret string
+}
+
+func (f SignerFunc) Sign(req *http.Request) error {
+	return f(req)
+}
+
+func (s Signers) Sign(req *http.Request) error {
+	for _, signer := range s {
+		if signer == nil {
+			continue
+		}
+		if err := signer.Sign(req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 构建Apollo的HMAC-SHA1签名器
+func NewHmacSigner(appId, secret string) *HmacSigner {
+	return &HmacSigner{AppId: appId, Secret: secret}
+}
+
+func (s *HmacSigner) Sign(req *http.Request) error {
+	if s.Secret == "" {
+		return nil
+	}
+	headers, err := buildHttpHeaders(req.URL.String(), s.AppId, s.Secret, timestamp())
+	if err != nil {
+		return err
+	}
+	for key, value := range headers {
+		if key != "" {
+			req.Header.Set(key, value)
+		}
+	}
+	return nil
+}
+
+// 设置固定请求头的签名器，如网关的Bearer Token
+func HeaderSigner(headers map[string]string) Signer {
+	return SignerFunc(func(req *http.Request) error {
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		return nil
+	})
+}
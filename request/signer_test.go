@@ -0,0 +1,76 @@
+package request
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHmacSigner(t *testing.T) {
+	req, _ := http.NewRequest(METHOD_GET, "http://81.68.181.139:8080/configs/apollo-client-test/default/application?ip=10.4.123.251", nil)
+	if err := NewHmacSigner("apollo-client-test", "").Sign(req); err != nil || req.Header.Get(HTTP_HEADER_AUTHORIZATION) != "" {
+		t.Fatal("HmacSigner should not sign request when secret is empty")
+	}
+	if err := NewHmacSigner("apollo-client-test", "4081edabfe4e4ba097cc16defc526c2f").Sign(req); err != nil {
+		t.Fatal(err)
+	}
+	ts := req.Header.Get(HTTP_HEADER_TIMESTAMP)
+	expect := "Apollo apollo-client-test:" + signature(ts, "/configs/apollo-client-test/default/application?ip=10.4.123.251", "4081edabfe4e4ba097cc16defc526c2f")
+	if req.Header.Get(HTTP_HEADER_AUTHORIZATION) != expect {
+		t.Fatal(fmt.Sprintf("unexpected authorization header: %s", req.Header.Get(HTTP_HEADER_AUTHORIZATION)))
+	}
+}
+
+func TestSigners(t *testing.T) {
+	req, _ := http.NewRequest(METHOD_GET, "http://config.example.com/configs", nil)
+	signers := Signers{
+		HeaderSigner(map[string]string{"Authorization": "Bearer token"}),
+		nil,
+		SignerFunc(func(req *http.Request) error {
+			req.Header.Set("X-Request-Id", "1")
+			return nil
+		}),
+	}
+	if err := signers.Sign(req); err != nil {
+		t.Fatal(err)
+	}
+	if req.Header.Get("Authorization") != "Bearer token" || req.Header.Get("X-Request-Id") != "1" {
+		t.Fatal(fmt.Sprintf("unexpected headers: %v", req.Header))
+	}
+
+	signErr := errors.New("sign error")
+	signers = Signers{SignerFunc(func(req *http.Request) error { return signErr })}
+	if err := signers.Sign(req); !errors.Is(err, signErr) {
+		t.Fatal(fmt.Sprintf("Signers should return error of signer, err: %v", err))
+	}
+}
+
+func TestSenderWithSigner(t *testing.T) {
+	var requests int
+	sender := &Sender{
+		HttpClient: NewHttpClient(roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			requests++
+			if r.Header.Get(HTTP_HEADER_AUTHORIZATION) != "Bearer token" {
+				t.Error("custom signer should replace default signature")
+			}
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(`[]`)), Request: r}, nil
+		})),
+		Signer: HeaderSigner(map[string]string{HTTP_HEADER_AUTHORIZATION: "Bearer token"}),
+	}
+	if _, err := sender.SendGetRequest(context.Background(), "https://config.example.com/configs", "apollo-client-test", "4081edabfe4e4ba097cc16defc526c2f", time.Second, &Info{}); err != nil {
+		t.Fatal(err)
+	}
+
+	sender.Signer = SignerFunc(func(req *http.Request) error { return errors.New("sign error") })
+	if _, err := sender.SendGetRequest(context.Background(), "https://config.example.com/configs", "apollo-client-test", "", time.Second, &Info{}); err == nil {
+		t.Fatal("SendGetRequest should return error when signing fails")
+	}
+	if requests != 1 {
+		t.Fatal(fmt.Sprintf("request should not be sent when signing fails, requests: %d", requests))
+	}
+}
@@ -0,0 +1,82 @@
+package request
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+const DEFAULT_TLS_MIN_VERSION = tls.VersionTLS12
+
+// TLS配置，默认校验服务端证书
+type TLSConfig struct {
+	//根证书文件（PEM格式），为空时使用系统根证书
+	CAFile  string
+	RootCAs *x509.CertPool
+	//客户端证书和私钥文件（PEM格式），用于mTLS
+	CertFile     string
+	KeyFile      string
+	Certificates []tls.Certificate
+	//覆盖校验证书时使用的服务端名称
+	ServerName string
+	//最低TLS版本，为0时使用 tls.VersionTLS12
+	MinVersion uint16
+	//跳过服务端证书校验，仅用于测试环境，需要显式开启
+	InsecureSkipVerify bool
+}
+
+// 构建tls.Config
+func (c *TLSConfig) Build() (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		RootCAs:            c.RootCAs,
+		Certificates:       c.Certificates,
+		ServerName:         c.ServerName,
+		MinVersion:         c.MinVersion,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+	if tlsConfig.MinVersion == 0 {
+		tlsConfig.MinVersion = DEFAULT_TLS_MIN_VERSION
+	}
+
+	//加载根证书
+	if c.CAFile != "" {
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		if tlsConfig.RootCAs == nil {
+			tlsConfig.RootCAs = x509.NewCertPool()
+		}
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("No valid certificate found in CAFile: %s", c.CAFile)
+		}
+	}
+
+	//加载客户端证书
+	if c.CertFile != "" || c.KeyFile != "" {
+		if c.CertFile == "" || c.KeyFile == "" {
+			return nil, errors.New("CertFile and KeyFile must be set together")
+		}
+		certificate, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = append(tlsConfig.Certificates, certificate)
+	}
+
+	return tlsConfig, nil
+}
+
+// 基于TLS配置构建httpTransport
+func NewTLSTransport(c *TLSConfig) (*http.Transport, error) {
+	tlsConfig, err := c.Build()
+	if err != nil {
+		return nil, err
+	}
+	transport := NewHttpTransport()
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
+}
@@ -0,0 +1,81 @@
+package request
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTLSVerification(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	//默认校验服务端证书，自签名证书请求失败
+	if _, err := SendGetRequest(server.URL, "apollo-client-test", "", time.Second, &Info{}); err == nil {
+		t.Fatal("SendGetRequest should verify server certificate by default")
+	}
+
+	//通过RootCAs信任服务端证书
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(server.Certificate())
+	checkTLSRequest(t, server.URL, &TLSConfig{RootCAs: rootCAs})
+
+	//通过CAFile信任服务端证书
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	err := os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkTLSRequest(t, server.URL, &TLSConfig{CAFile: caFile})
+
+	//显式开启跳过证书校验
+	checkTLSRequest(t, server.URL, &TLSConfig{InsecureSkipVerify: true})
+}
+
+func TestTLSConfigBuild(t *testing.T) {
+	tlsConfig, err := (&TLSConfig{ServerName: "config.example.com"}).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tlsConfig.MinVersion != tls.VersionTLS12 || tlsConfig.InsecureSkipVerify || tlsConfig.ServerName != "config.example.com" {
+		t.Fatal(fmt.Sprintf("unexpected tls config: %v", tlsConfig))
+	}
+	if tlsConfig, _ = (&TLSConfig{MinVersion: tls.VersionTLS13}).Build(); tlsConfig.MinVersion != tls.VersionTLS13 {
+		t.Fatal("MinVersion should be applied")
+	}
+	if _, err = (&TLSConfig{CertFile: "client.pem"}).Build(); err == nil {
+		t.Fatal("Build should return error when KeyFile is empty")
+	}
+	if _, err = (&TLSConfig{CAFile: filepath.Join(t.TempDir(), "missing.pem")}).Build(); err == nil {
+		t.Fatal("Build should return error when CAFile not exists")
+	}
+	invalidCAFile := filepath.Join(t.TempDir(), "invalid.pem")
+	_ = os.WriteFile(invalidCAFile, []byte("invalid"), 0644)
+	if _, err = (&TLSConfig{CAFile: invalidCAFile}).Build(); err == nil {
+		t.Fatal("Build should return error when CAFile is invalid")
+	}
+}
+
+func checkTLSRequest(t *testing.T, requestUrl string, tlsConfig *TLSConfig) {
+	transport, err := NewTLSTransport(tlsConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := NewSender(NewHttpClient(transport)).SendGetRequest(context.Background(), requestUrl, "apollo-client-test", "", time.Second, &Info{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.IsGetDataSuccess() {
+		t.Fatal(fmt.Sprintf("unexpected response: %v", info))
+	}
+}